@@ -0,0 +1,157 @@
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/leofvo/bridgr/pkg/logger"
+)
+
+// Defaults of the global options
+const (
+	defaultConfigPath = "/etc/bridgr/config.yaml"
+	defaultLogLevel   = "info"
+	defaultLogFormat  = logger.FormatText
+)
+
+// envPrefix prefixes the environment variables setting the global options
+const envPrefix = "BRIDGR_"
+
+// options are the global options shared by every command
+type options struct {
+	configPath string
+	logLevel   string
+	logFormat  string
+}
+
+// register adds the global options to a flag set. Their defaults are read
+// from the BRIDGR_* environment variables, so that flags take precedence.
+func (o *options) register(fs *flag.FlagSet) {
+	fs.StringVar(&o.configPath, "config", envOr("CONFIG", defaultConfigPath), "path of the configuration file (env BRIDGR_CONFIG)")
+	fs.StringVar(&o.logLevel, "log-level", envOr("LOG_LEVEL", defaultLogLevel), "log level: debug, info, warn or error (env BRIDGR_LOG_LEVEL)")
+	fs.StringVar(&o.logFormat, "log-format", envOr("LOG_FORMAT", defaultLogFormat), "log format: text or json (env BRIDGR_LOG_FORMAT)")
+}
+
+// envOr returns the value of a BRIDGR_* environment variable, or fallback when unset
+func envOr(name, fallback string) string {
+	if value, ok := os.LookupEnv(envPrefix + name); ok && value != "" {
+		return value
+	}
+	return fallback
+}
+
+// command is a subcommand of the bridgr binary
+type command struct {
+	name        string
+	usage       string
+	description string
+	flags       func(fs *flag.FlagSet)
+	run         func(opts *options, args []string) error
+}
+
+// defaultCommand runs when no command is given
+const defaultCommand = "serve"
+
+// commands returns the available commands
+func commands() []*command {
+	testSource := &testSourceFlags{}
+
+	return []*command{
+		{
+			name:        "serve",
+			usage:       "serve [flags]",
+			description: "Poll the sources and deliver notifications (default)",
+			run:         serve,
+		},
+		{
+			name:        "validate",
+			usage:       "validate [flags] [file...]",
+			description: "Check configuration files and report every problem with its position",
+			run:         validate,
+		},
+		{
+			name:        "test-source",
+			usage:       "test-source [flags] (-source ID | -snippet FILE | -url URL)",
+			description: "Fetch a source once and print its items, including the skipped ones and why",
+			flags:       testSource.register,
+			run:         testSource.run,
+		},
+	}
+}
+
+// errUsage reports invalid arguments, after the usage has been printed
+var errUsage = errors.New("invalid usage")
+
+// execute parses the arguments, runs the selected command and returns the
+// process exit code
+func execute(args []string, stderr io.Writer) int {
+	name := defaultCommand
+	if len(args) > 0 && !strings.HasPrefix(args[0], "-") {
+		name, args = args[0], args[1:]
+	}
+
+	if name == "help" {
+		printUsage(stderr)
+		return 0
+	}
+
+	var cmd *command
+	for _, c := range commands() {
+		if c.name == name {
+			cmd = c
+		}
+	}
+	if cmd == nil {
+		fmt.Fprintf(stderr, "Unknown command: %s\n\n", name)
+		printUsage(stderr)
+		return 2
+	}
+
+	opts := &options{}
+	fs := flag.NewFlagSet(cmd.name, flag.ContinueOnError)
+	fs.SetOutput(stderr)
+	fs.Usage = func() {
+		fmt.Fprintf(stderr, "Usage: bridgr %s\n\n%s\n\nFlags:\n", cmd.usage, cmd.description)
+		fs.PrintDefaults()
+	}
+	opts.register(fs)
+	if cmd.flags != nil {
+		cmd.flags(fs)
+	}
+
+	if err := fs.Parse(args); err != nil {
+		if errors.Is(err, flag.ErrHelp) {
+			return 0
+		}
+		return 2
+	}
+
+	if err := logger.Init(opts.logLevel, opts.logFormat); err != nil {
+		fmt.Fprintf(stderr, "Failed to initialize logger: %v\n", err)
+		return 2
+	}
+
+	if err := cmd.run(opts, fs.Args()); err != nil {
+		if errors.Is(err, errUsage) {
+			fs.Usage()
+			return 2
+		}
+		fmt.Fprintf(stderr, "Error: %v\n", err)
+		return 1
+	}
+	return 0
+}
+
+// printUsage prints the available commands
+func printUsage(w io.Writer) {
+	fmt.Fprintf(w, "Usage: bridgr [command] [flags]\n\nCommands:\n")
+	for _, c := range commands() {
+		fmt.Fprintf(w, "  %-12s %s\n", c.name, c.description)
+	}
+	fmt.Fprintf(w, "  %-12s %s\n", "help", "Show this help")
+	fmt.Fprintf(w, "\nRun 'bridgr <command> -h' for the flags of a command.\n")
+}
@@ -0,0 +1,119 @@
+package main
+
+import (
+	"fmt"
+	"reflect"
+
+	"github.com/leofvo/bridgr/internal/config"
+	"github.com/leofvo/bridgr/internal/domain"
+	"github.com/leofvo/bridgr/internal/exporters"
+	"github.com/leofvo/bridgr/internal/filters"
+	"github.com/leofvo/bridgr/internal/routing"
+	"github.com/leofvo/bridgr/internal/sources"
+	"github.com/leofvo/bridgr/internal/window"
+)
+
+// builtSource is a source instance with the configuration it was built from
+type builtSource struct {
+	group  string
+	config config.SourceConfig
+	source domain.Source
+}
+
+// builtExporter is an exporter instance with the configuration it was built from
+type builtExporter struct {
+	group    string
+	config   config.ExporterConfig
+	exporter domain.Exporter
+}
+
+// components are the sources, exporters and compiled rules of a configuration
+type components struct {
+	sources   []domain.Source
+	exporters []domain.Exporter
+	filters   *filters.Set
+	router    *routing.Router
+	windows   *window.Set
+
+	builtSources   map[string]builtSource
+	builtExporters map[string]builtExporter
+}
+
+// buildComponents creates the components of a configuration. Sources and
+// exporters whose configuration did not change since previous are reused, so
+// that their schedules and in-flight deliveries are left untouched.
+func buildComponents(cfg *config.Config, previous *components, sourceFactory *sources.Factory, exporterFactory *exporters.Factory) (*components, error) {
+	c := &components{
+		builtSources:   make(map[string]builtSource),
+		builtExporters: make(map[string]builtExporter),
+	}
+
+	for _, group := range cfg.Groups {
+		// Create sources
+		for _, sourceCfg := range group.Sources {
+			id := sourceCfg.ResolveID(group.Name)
+			if previous != nil {
+				if built, ok := previous.builtSources[id]; ok && built.group == group.Name && reflect.DeepEqual(built.config, sourceCfg) {
+					c.addSource(id, built)
+					continue
+				}
+			}
+
+			sourceCfg := sourceCfg
+			source, err := sourceFactory.CreateSource(&sourceCfg, group.Name)
+			if err != nil {
+				return nil, fmt.Errorf("failed to create source: group=%s error=%w", group.Name, err)
+			}
+			c.addSource(id, builtSource{group: group.Name, config: sourceCfg, source: source})
+		}
+
+		// Create exporters
+		for _, exporterCfg := range group.Exporters {
+			id := exporterCfg.ResolveID(group.Name)
+			if previous != nil {
+				if built, ok := previous.builtExporters[id]; ok && built.group == group.Name && reflect.DeepEqual(built.config, exporterCfg) {
+					c.addExporter(id, built)
+					continue
+				}
+			}
+
+			exporterCfg := exporterCfg
+			exporter, err := exporterFactory.CreateExporter(&exporterCfg, group.Name)
+			if err != nil {
+				return nil, fmt.Errorf("failed to create exporter: group=%s error=%w", group.Name, err)
+			}
+			c.addExporter(id, builtExporter{group: group.Name, config: exporterCfg, exporter: exporter})
+		}
+	}
+
+	var err error
+
+	// Compile item filters
+	if c.filters, err = filters.NewSet(cfg); err != nil {
+		return nil, fmt.Errorf("failed to compile filters: %w", err)
+	}
+
+	// Compile routing conditions
+	if c.router, err = routing.NewRouter(cfg); err != nil {
+		return nil, fmt.Errorf("failed to compile routes: %w", err)
+	}
+
+	// Compile delivery windows
+	if c.windows, err = window.NewSet(cfg); err != nil {
+		return nil, fmt.Errorf("failed to compile delivery windows: %w", err)
+	}
+
+	return c, nil
+}
+
+// addSource adds a source to the components
+func (c *components) addSource(id string, built builtSource) {
+	c.builtSources[id] = built
+	c.sources = append(c.sources, built.source)
+}
+
+// addExporter adds an exporter to the components
+func (c *components) addExporter(id string, built builtExporter) {
+	c.builtExporters[id] = built
+	c.exporters = append(c.exporters, built.exporter)
+}
@@ -11,75 +11,99 @@ import (
 
 	"github.com/gorilla/mux"
 	"github.com/leofvo/bridgr/internal/config"
-	"github.com/leofvo/bridgr/internal/domain"
 	"github.com/leofvo/bridgr/internal/exporters"
 	"github.com/leofvo/bridgr/internal/handlers"
+	"github.com/leofvo/bridgr/internal/metrics"
 	"github.com/leofvo/bridgr/internal/services"
 	"github.com/leofvo/bridgr/internal/sources"
 	"github.com/leofvo/bridgr/internal/store"
 	"github.com/leofvo/bridgr/pkg/logger"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
 )
 
 func main() {
-	// Initialize logger
-	if err := logger.Init("info"); err != nil {
-		fmt.Printf("Failed to initialize logger: %v\n", err)
-		os.Exit(1)
+	os.Exit(execute(os.Args[1:], os.Stderr))
+}
+
+// serve polls the sources and delivers notifications until interrupted
+func serve(opts *options, args []string) error {
+	if len(args) > 0 {
+		return errUsage
 	}
 
 	// Load configuration
-	cfg, err := config.LoadConfig("/etc/bridgr/config.yaml")
+	configPath := opts.configPath
+	content, err := os.ReadFile(configPath)
+	if err != nil {
+		return fmt.Errorf("failed to read configuration: path=%s error=%w", configPath, err)
+	}
+	cfg, err := config.ParseConfig(content)
 	if err != nil {
-		logger.Fatal("Failed to load configuration: %v", err)
+		return fmt.Errorf("failed to load configuration: %w", err)
 	}
+	logger.SetSecrets(cfg.Secrets())
 
 	// Validate configuration
 	if err := config.ValidateConfig(cfg); err != nil {
-		logger.Fatal("Invalid configuration: %v", err)
+		return fmt.Errorf("invalid configuration: %w", err)
 	}
 
 	// Initialize Redis store
 	redisStore, err := store.NewRedisStore(&cfg.Redis)
 	if err != nil {
-		logger.Fatal("Failed to initialize Redis store: %v", err)
+		return fmt.Errorf("failed to initialize Redis store: %w", err)
 	}
 	defer redisStore.Close()
 
 	// Create factories
-	sourceFactory := sources.NewFactory()
+	sourceFactory := sources.NewFactory(redisStore)
 	exporterFactory := exporters.NewFactory()
 
-	// Create sources and exporters
-	var allSources []domain.Source
-	var allExporters []domain.Exporter
-
-	for _, group := range cfg.Groups {
-		// Create sources
-		for _, sourceCfg := range group.Sources {
-			source, err := sourceFactory.CreateSource(&sourceCfg, group.Name)
-			if err != nil {
-				logger.Fatal("Failed to create source: error=%v group=%s", err, group.Name)
-			}
-			allSources = append(allSources, source)
-		}
-
-		// Create exporters
-		for _, exporterCfg := range group.Exporters {
-			exporter, err := exporterFactory.CreateExporter(&exporterCfg, group.Name)
-			if err != nil {
-				logger.Fatal("Failed to create exporter: error=%v group=%s", err, group.Name)
-			}
-			allExporters = append(allExporters, exporter)
+	// Create sources, exporters and compiled rules
+	built, err := buildComponents(cfg, nil, sourceFactory, exporterFactory)
+	if err != nil {
+		return fmt.Errorf("failed to build components: %w", err)
+	}
+	allSources := built.sources
+	allExporters := built.exporters
+
+	// Migrate deduplication keys written before exporters had their own ID
+	var webhookIDs []string
+	for _, exporter := range allExporters {
+		if exporter.GetType() == "webhook" {
+			webhookIDs = append(webhookIDs, exporter.GetID())
 		}
 	}
+	migrated, err := redisStore.MigrateLegacyProcessedKeys(context.Background(), "webhook", webhookIDs)
+	if err != nil {
+		return fmt.Errorf("failed to migrate processed keys: %w", err)
+	}
+	if migrated > 0 {
+		logger.Info("Migrated legacy processed keys: count=%d exporters=%d", migrated, len(webhookIDs))
+	}
 
 	// Create services
-	notificationService := services.NewNotificationService(redisStore)
-	schedulerService := services.NewSchedulerService(notificationService, allSources, allExporters)
+	deliveryQueue := services.NewDeliveryQueue(redisStore, &cfg.Delivery, allExporters)
+	digestService := services.NewDigestService(redisStore, deliveryQueue, built.windows, allExporters)
+	holdService := services.NewHoldService(redisStore, deliveryQueue, built.windows, allExporters)
+	notificationService := services.NewNotificationService(redisStore, deliveryQueue, digestService, holdService, built.filters, built.router)
+	schedulerService := services.NewSchedulerService(notificationService, redisStore, deliveryQueue, allSources, allExporters, &cfg.Polling)
+
+	// Expose the delivery queue depth
+	metrics.RegisterQueueDepth(deliveryQueue.Depth)
 
 	// Create router
 	router := mux.NewRouter()
 	router.Handle("/health", handlers.NewHealthHandler()).Methods("GET")
+	router.Handle("/livez", handlers.NewHealthHandler()).Methods("GET")
+	router.Handle("/readyz", handlers.NewReadinessHandler(redisStore, schedulerService, deliveryQueue, &cfg.Server.Readiness)).Methods("GET")
+	router.Handle("/metrics", promhttp.Handler()).Methods("GET")
+	// Dead letters hold item payloads, they are only exposed with an admin token
+	if cfg.Server.AdminToken != "" {
+		handlers.NewDeadLetterHandler(redisStore, deliveryQueue, cfg.Server.AdminToken).Register(router)
+	} else {
+		logger.Info("Dead-letter endpoints disabled, set server.admin_token to enable them")
+	}
 
 	// Create HTTP server
 	server := &http.Server{
@@ -91,28 +115,64 @@ func main() {
 	ctx, cancel := context.WithCancel(context.Background())
 	defer cancel()
 
+	// Start delivery queue, digests and held item releases
+	deliveryQueue.Start()
+	digestService.Start(ctx)
+	holdService.Start(ctx)
+
 	// Start scheduler
 	if err := schedulerService.Start(ctx); err != nil {
-		logger.Fatal("Failed to start scheduler: %v", err)
+		return fmt.Errorf("failed to start scheduler: %w", err)
+	}
+
+	// Reload the configuration when its file changes
+	configReloader := &reloader{
+		path:            configPath,
+		content:         content,
+		config:          cfg,
+		components:      built,
+		sourceFactory:   sourceFactory,
+		exporterFactory: exporterFactory,
+		queue:           deliveryQueue,
+		digests:         digestService,
+		holds:           holdService,
+		notifications:   notificationService,
+		scheduler:       schedulerService,
+	}
+	if err := configReloader.watch(ctx); err != nil {
+		logger.Error("Configuration reload disabled: %v", err)
 	}
 
 	// Start HTTP server
+	serverErr := make(chan error, 1)
 	go func() {
 		logger.Info("Starting HTTP server on port %d", cfg.Server.Port)
 		if err := server.ListenAndServe(); err != nil && err != http.ErrServerClosed {
-			logger.Fatal("Failed to start HTTP server: %v", err)
+			serverErr <- fmt.Errorf("failed to start HTTP server: %w", err)
 		}
 	}()
 
-	// Wait for interrupt signal
+	// Wait for interrupt signal, or for the HTTP server to fail
 	sigChan := make(chan os.Signal, 1)
 	signal.Notify(sigChan, syscall.SIGINT, syscall.SIGTERM)
-	<-sigChan
+	var runErr error
+	select {
+	case <-sigChan:
+	case runErr = <-serverErr:
+		logger.Error("%v", runErr)
+	}
 
 	// Shutdown gracefully
 	logger.Info("Shutting down...")
 	cancel()
 	schedulerService.Stop()
+	digestService.Stop()
+	holdService.Stop()
+
+	// Let in-flight deliveries finish before exiting
+	drainCtx, drainCancel := context.WithTimeout(context.Background(), cfg.Delivery.DrainTimeout)
+	defer drainCancel()
+	deliveryQueue.Shutdown(drainCtx)
 
 	shutdownCtx, shutdownCancel := context.WithTimeout(context.Background(), 10*time.Second)
 	defer shutdownCancel()
@@ -120,4 +180,6 @@ func main() {
 	if err := server.Shutdown(shutdownCtx); err != nil {
 		logger.Error("Failed to shutdown HTTP server: %v", err)
 	}
-} 
\ No newline at end of file
+
+	return runErr
+}
@@ -0,0 +1,182 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"reflect"
+	"time"
+
+	"github.com/fsnotify/fsnotify"
+	"github.com/leofvo/bridgr/internal/config"
+	"github.com/leofvo/bridgr/internal/exporters"
+	"github.com/leofvo/bridgr/internal/services"
+	"github.com/leofvo/bridgr/internal/sources"
+	"github.com/leofvo/bridgr/pkg/logger"
+)
+
+// reloadDebounce groups the bursts of events written by editors and
+// configuration management tools into a single reload
+const reloadDebounce = 500 * time.Millisecond
+
+// reloader applies configuration changes to the running services
+type reloader struct {
+	path            string
+	content         []byte
+	config          *config.Config
+	components      *components
+	sourceFactory   *sources.Factory
+	exporterFactory *exporters.Factory
+	queue           *services.DeliveryQueue
+	digests         *services.DigestService
+	holds           *services.HoldService
+	notifications   *services.NotificationService
+	scheduler       *services.SchedulerService
+}
+
+// watch reloads the configuration whenever its file changes, until the
+// context is cancelled. The directory is watched rather than the file so
+// that files replaced by a rename or a symlink swap are followed.
+func (r *reloader) watch(ctx context.Context) error {
+	watcher, err := fsnotify.NewWatcher()
+	if err != nil {
+		return fmt.Errorf("failed to create config watcher: %w", err)
+	}
+
+	dir := filepath.Dir(r.path)
+	if err := watcher.Add(dir); err != nil {
+		watcher.Close()
+		return fmt.Errorf("failed to watch config directory: path=%s error=%w", dir, err)
+	}
+
+	go func() {
+		defer watcher.Close()
+
+		debounce := time.NewTimer(reloadDebounce)
+		debounce.Stop()
+		defer debounce.Stop()
+
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case event, ok := <-watcher.Events:
+				if !ok {
+					return
+				}
+				if event.Has(fsnotify.Chmod) {
+					continue
+				}
+				debounce.Reset(reloadDebounce)
+			case err, ok := <-watcher.Errors:
+				if !ok {
+					return
+				}
+				logger.Error("Config watcher error: %v", err)
+			case <-debounce.C:
+				r.reload()
+			}
+		}
+	}()
+
+	logger.Info("Watching configuration for changes: path=%s", r.path)
+	return nil
+}
+
+// reload loads, validates and applies the configuration file. An invalid
+// configuration is rejected and the running one is kept.
+func (r *reloader) reload() {
+	content, err := os.ReadFile(r.path)
+	if err != nil {
+		logger.Error("Failed to read configuration, keeping the running one: path=%s error=%v", r.path, err)
+		return
+	}
+	if bytes.Equal(content, r.content) {
+		return
+	}
+
+	cfg, err := config.ParseConfig(content)
+	if err != nil {
+		logger.Error("Failed to load configuration, keeping the running one: %v", err)
+		return
+	}
+	// Redact the secrets of both configurations until the new one is applied
+	logger.SetSecrets(append(r.config.Secrets(), cfg.Secrets()...))
+
+	if err := config.ValidateConfig(cfg); err != nil {
+		logger.Error("Invalid configuration, keeping the running one: %v", err)
+		return
+	}
+
+	built, err := buildComponents(cfg, r.components, r.sourceFactory, r.exporterFactory)
+	if err != nil {
+		logger.Error("Failed to apply configuration, keeping the running one: %v", err)
+		return
+	}
+
+	r.warnRestartRequired(cfg)
+
+	// Exporters are made available before the sources that may use them start
+	r.queue.SetExporters(built.exporters)
+	r.digests.Update(built.windows, built.exporters)
+	r.holds.Update(built.windows, built.exporters)
+	r.notifications.Update(built.filters, built.router)
+	r.scheduler.Update(built.sources, built.exporters, &cfg.Polling)
+
+	logger.Info("Reloaded configuration: sources=%d exporters=%d %s", len(built.sources), len(built.exporters), diffSummary(r.components, built))
+
+	r.content = content
+	r.config = cfg
+	r.components = built
+	logger.SetSecrets(cfg.Secrets())
+}
+
+// warnRestartRequired logs the changed settings that only apply on restart
+func (r *reloader) warnRestartRequired(cfg *config.Config) {
+	if !reflect.DeepEqual(r.config.Redis, cfg.Redis) {
+		logger.Warn("Redis settings changed, restart to apply them")
+	}
+	if !reflect.DeepEqual(r.config.Server, cfg.Server) {
+		logger.Warn("Server settings changed, restart to apply them")
+	}
+	if !reflect.DeepEqual(r.config.Delivery, cfg.Delivery) {
+		logger.Warn("Delivery settings changed, restart to apply them")
+	}
+}
+
+// diffSummary describes the sources and exporters added, removed or changed
+// between two sets of components
+func diffSummary(previous, current *components) string {
+	var sourcesAdded, sourcesRemoved, sourcesChanged int
+	for id, built := range current.builtSources {
+		if old, ok := previous.builtSources[id]; !ok {
+			sourcesAdded++
+		} else if old.source != built.source {
+			sourcesChanged++
+		}
+	}
+	for id := range previous.builtSources {
+		if _, ok := current.builtSources[id]; !ok {
+			sourcesRemoved++
+		}
+	}
+
+	var exportersAdded, exportersRemoved, exportersChanged int
+	for id, built := range current.builtExporters {
+		if old, ok := previous.builtExporters[id]; !ok {
+			exportersAdded++
+		} else if old.exporter != built.exporter {
+			exportersChanged++
+		}
+	}
+	for id := range previous.builtExporters {
+		if _, ok := current.builtExporters[id]; !ok {
+			exportersRemoved++
+		}
+	}
+
+	return fmt.Sprintf("sources_added=%d sources_removed=%d sources_changed=%d exporters_added=%d exporters_removed=%d exporters_changed=%d",
+		sourcesAdded, sourcesRemoved, sourcesChanged, exportersAdded, exportersRemoved, exportersChanged)
+}
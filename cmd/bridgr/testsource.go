@@ -0,0 +1,218 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"os"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/leofvo/bridgr/internal/config"
+	"github.com/leofvo/bridgr/internal/domain"
+	"github.com/leofvo/bridgr/internal/filters"
+	"github.com/leofvo/bridgr/internal/sources"
+	"github.com/leofvo/bridgr/internal/store"
+	"github.com/leofvo/bridgr/internal/utils"
+	"github.com/leofvo/bridgr/pkg/logger"
+)
+
+// Output formats of the test-source command
+const (
+	outputTable = "table"
+	outputJSON  = "json"
+)
+
+// testSourceTimeout bounds the fetch of the tested source
+const testSourceTimeout = time.Minute
+
+// maxTableTitle is the width of the title column of the table output
+const maxTableTitle = 60
+
+// testSourceFlags are the flags of the test-source command
+type testSourceFlags struct {
+	source    string
+	snippet   string
+	url       string
+	typ       string
+	group     string
+	output    string
+	withState bool
+}
+
+// register adds the test-source flags to a flag set
+func (f *testSourceFlags) register(fs *flag.FlagSet) {
+	fs.StringVar(&f.source, "source", "", "ID or URL of a source of the configuration file")
+	fs.StringVar(&f.snippet, "snippet", "", "YAML file holding a single source configuration")
+	fs.StringVar(&f.url, "url", "", "URL of the source to test")
+	fs.StringVar(&f.typ, "type", config.SourceTypeRSS, "type of the source given with -url")
+	fs.StringVar(&f.group, "group", "test", "group of the source given with -url or -snippet")
+	fs.StringVar(&f.output, "output", outputTable, "output format: table or json")
+	fs.BoolVar(&f.withState, "with-state", false, "skip the items already seen, using the source state stored in Redis")
+}
+
+// testedItem is an item of the test-source output
+type testedItem struct {
+	domain.Item
+	Skipped bool   `json:"skipped"`
+	Reason  string `json:"reason,omitempty"`
+}
+
+// run fetches a source once and prints the items it would return, along
+// with the skipped items and the reason they are skipped
+func (f *testSourceFlags) run(opts *options, args []string) error {
+	if len(args) > 0 || f.output != outputTable && f.output != outputJSON {
+		return errUsage
+	}
+
+	// Keep the standard output for the items
+	logger.Get().SetOutput(os.Stderr)
+
+	cfg, sourceCfg, group, err := f.sourceConfig(opts)
+	if err != nil {
+		return err
+	}
+
+	var sourceStore domain.Store
+	if f.withState {
+		redisStore, err := store.NewRedisStore(&cfg.Redis)
+		if err != nil {
+			return err
+		}
+		defer redisStore.Close()
+		sourceStore = redisStore
+	}
+
+	source, err := sources.NewFactory(sourceStore).CreateSource(sourceCfg, group.Name)
+	if err != nil {
+		return err
+	}
+	previewer, ok := source.(interface {
+		Preview(ctx context.Context) ([]domain.Item, []domain.SkippedItem, error)
+	})
+	if !ok {
+		return fmt.Errorf("source type does not support previews: %s", source.GetType())
+	}
+
+	filterSet, err := filters.NewSet(&config.Config{
+		Groups: []config.GroupConfig{{
+			Name:    group.Name,
+			Sources: []config.SourceConfig{*sourceCfg},
+			Filters: group.Filters,
+		}},
+	})
+	if err != nil {
+		return err
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), testSourceTimeout)
+	defer cancel()
+
+	items, skipped, err := previewer.Preview(ctx)
+	if err != nil {
+		return err
+	}
+
+	// Group and source filters apply to every exporter of the group
+	now := time.Now()
+	results := make([]testedItem, 0, len(items)+len(skipped))
+	for _, item := range items {
+		ok, reason := filterSet.Allow(item, "", now)
+		results = append(results, testedItem{Item: item, Skipped: !ok, Reason: reason})
+	}
+	for _, skippedItem := range skipped {
+		results = append(results, testedItem{Item: skippedItem.Item, Skipped: true, Reason: skippedItem.Reason})
+	}
+
+	if f.output == outputJSON {
+		encoder := json.NewEncoder(os.Stdout)
+		encoder.SetIndent("", "  ")
+		return encoder.Encode(results)
+	}
+	return printTestedItems(source.GetID(), results)
+}
+
+// sourceConfig returns the configuration of the tested source and its group,
+// from the configuration file, a snippet or the flags
+func (f *testSourceFlags) sourceConfig(opts *options) (*config.Config, *config.SourceConfig, *config.GroupConfig, error) {
+	selected := 0
+	for _, set := range []bool{f.source != "", f.snippet != "", f.url != ""} {
+		if set {
+			selected++
+		}
+	}
+	if selected != 1 {
+		return nil, nil, nil, errUsage
+	}
+
+	// The configuration file provides the source or the Redis settings
+	cfg := &config.Config{}
+	if f.source != "" || f.withState {
+		var err error
+		if cfg, err = config.LoadConfig(opts.configPath); err != nil {
+			return nil, nil, nil, err
+		}
+		logger.SetSecrets(cfg.Secrets())
+	}
+
+	switch {
+	case f.source != "":
+		for i := range cfg.Groups {
+			group := &cfg.Groups[i]
+			for j := range group.Sources {
+				source := &group.Sources[j]
+				if source.ResolveID(group.Name) == f.source || source.URL == f.source {
+					return cfg, source, group, nil
+				}
+			}
+		}
+		return nil, nil, nil, fmt.Errorf("source not found in configuration: %s", f.source)
+
+	case f.snippet != "":
+		data, err := os.ReadFile(f.snippet)
+		if err != nil {
+			return nil, nil, nil, fmt.Errorf("failed to read snippet: %w", err)
+		}
+		snippet, err := config.ParseSourceConfig(data, f.group)
+		if err != nil {
+			return nil, nil, nil, err
+		}
+		logger.SetSecrets(append(cfg.Secrets(), snippet.Secrets()...))
+		group := &snippet.Groups[0]
+		return cfg, &group.Sources[0], group, nil
+
+	default:
+		source := &config.SourceConfig{Type: f.typ, URL: f.url}
+		return cfg, source, &config.GroupConfig{Name: f.group}, nil
+	}
+}
+
+// printTestedItems prints the tested items as a table
+func printTestedItems(sourceID string, results []testedItem) error {
+	var returned int
+	for _, result := range results {
+		if !result.Skipped {
+			returned++
+		}
+	}
+	fmt.Fprintf(os.Stdout, "Source %s: %d items, %d skipped\n\n", sourceID, returned, len(results)-returned)
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "STATUS\tPUBLISHED\tID\tTITLE\tREASON")
+	for _, result := range results {
+		status := "ok"
+		if result.Skipped {
+			status = "skipped"
+		}
+		title := strings.Join(strings.Fields(result.Title), " ")
+		fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\n",
+			status,
+			result.PublishedAt.UTC().Format(time.RFC3339),
+			result.ID,
+			utils.Truncate(title, maxTableTitle),
+			result.Reason)
+	}
+	return w.Flush()
+}
@@ -0,0 +1,104 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/leofvo/bridgr/internal/config"
+	"github.com/leofvo/bridgr/internal/exporters"
+	"github.com/leofvo/bridgr/internal/routing"
+	"github.com/leofvo/bridgr/internal/sources"
+	"github.com/leofvo/bridgr/pkg/logger"
+)
+
+// validate reports every problem of the configuration files given as
+// arguments, or of the configured file, and fails if any is found
+func validate(opts *options, args []string) error {
+	paths := args
+	if len(paths) == 0 {
+		paths = []string{opts.configPath}
+	}
+
+	var problems int
+	for _, path := range paths {
+		d, err := config.Diagnose(path)
+		if err != nil {
+			return err
+		}
+		if d.Config != nil {
+			logger.SetSecrets(d.Config.Secrets())
+
+			// Components are only built from a configuration without problems
+			valid := len(d.Problems) == 0
+			checkConditions(d)
+			if valid {
+				checkComponents(d)
+			}
+		}
+
+		for _, problem := range d.Problems {
+			fmt.Fprintf(os.Stdout, "%s:%s\n", path, logger.Redact(problem.String()))
+		}
+		if len(d.Problems) == 0 {
+			fmt.Fprintf(os.Stdout, "%s: configuration is valid\n", path)
+		}
+		problems += len(d.Problems)
+	}
+
+	if problems > 0 {
+		return fmt.Errorf("configuration problems found: %d", problems)
+	}
+	return nil
+}
+
+// checkComponents builds the sources and exporters of a valid configuration,
+// reporting the errors only detected at startup (payload templates,
+// authentication options)
+func checkComponents(d *config.Diagnostics) {
+	sourceFactory := sources.NewFactory(nil)
+	exporterFactory := exporters.NewFactory()
+
+	for i, group := range d.Config.Groups {
+		for j, sourceCfg := range group.Sources {
+			if _, err := sourceFactory.CreateSource(&sourceCfg, group.Name); err != nil {
+				d.Add(fmt.Sprintf("groups[%d].sources[%d]", i, j), "%v", err)
+			}
+		}
+
+		for j, exporterCfg := range group.Exporters {
+			if _, err := exporterFactory.CreateExporter(&exporterCfg, group.Name); err != nil {
+				d.Add(fmt.Sprintf("groups[%d].exporters[%d]", i, j), "%v", err)
+			}
+		}
+	}
+}
+
+// checkConditions compiles the routing conditions of the routes and exporters
+func checkConditions(d *config.Diagnostics) {
+	for i, group := range d.Config.Groups {
+		for j, exporterCfg := range group.Exporters {
+			if exporterCfg.When == "" {
+				continue
+			}
+			if _, err := routing.Compile(exporterCfg.When); err != nil {
+				d.Add(fmt.Sprintf("groups[%d].exporters[%d].when", i, j), "invalid condition: %s", firstLine(err))
+			}
+		}
+	}
+
+	for i, route := range d.Config.Routes {
+		if route.When == "" {
+			continue
+		}
+		if _, err := routing.Compile(route.When); err != nil {
+			d.Add(fmt.Sprintf("routes[%d].when", i), "invalid condition: %s", firstLine(err))
+		}
+	}
+}
+
+// firstLine returns the first line of an error message, leaving out the
+// source excerpt appended by the expression compiler
+func firstLine(err error) string {
+	return strings.SplitN(err.Error(), "\n", 2)[0]
+}
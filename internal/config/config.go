@@ -1,22 +1,42 @@
 package config
 
 import (
+	"bytes"
 	"fmt"
+	"net"
+	"net/url"
 	"os"
+	"regexp"
 	"strconv"
 	"time"
 
+	"github.com/robfig/cron/v3"
 	"github.com/spf13/viper"
 )
 
 // LoadConfig loads the configuration from the specified path and environment variables
 func LoadConfig(path string) (*Config, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read config file: %w", err)
+	}
+	return ParseConfig(data)
+}
+
+// ParseConfig loads the configuration from the content of a configuration
+// file and environment variables
+func ParseConfig(data []byte) (*Config, error) {
+	// Resolve environment and file references
+	data, secrets, err := interpolateYAML(data)
+	if err != nil {
+		return nil, err
+	}
+
 	v := viper.New()
-	v.SetConfigFile(path)
 	v.SetConfigType("yaml")
 
 	// Read config file
-	if err := v.ReadInConfig(); err != nil {
+	if err := v.ReadConfig(bytes.NewReader(data)); err != nil {
 		return nil, fmt.Errorf("failed to read config file: %w", err)
 	}
 
@@ -24,22 +44,100 @@ func LoadConfig(path string) (*Config, error) {
 	if err := v.Unmarshal(&config); err != nil {
 		return nil, fmt.Errorf("failed to unmarshal config: %w", err)
 	}
+	config.secrets = secrets
 
 	// Override with environment variables
 	overrideFromEnv(&config)
+	if len(config.Redis.Password) >= minSecretLength {
+		config.secrets = append(config.secrets, config.Redis.Password)
+	}
 
 	// Set defaults
 	if config.Server.Port == 0 {
 		config.Server.Port = 8080
 	}
 
+	if config.Server.Readiness.SourceFailureThreshold == 0 {
+		config.Server.Readiness.SourceFailureThreshold = time.Hour
+	}
+
+	if config.Server.Readiness.ExporterFailureThreshold == 0 {
+		config.Server.Readiness.ExporterFailureThreshold = 5
+	}
+
 	if config.Redis.TTL == 0 {
 		config.Redis.TTL = 7 * 24 * time.Hour // 7 days default TTL
 	}
 
+	if config.Delivery.Workers == 0 {
+		config.Delivery.Workers = 10
+	}
+
+	if config.Delivery.QueueSize == 0 {
+		config.Delivery.QueueSize = 1000
+	}
+
+	if config.Delivery.DrainTimeout == 0 {
+		config.Delivery.DrainTimeout = 30 * time.Second
+	}
+
+	if config.Polling.Backoff.Multiplier == 0 {
+		config.Polling.Backoff.Multiplier = 2
+	}
+
+	if config.Polling.Backoff.MaxInterval == 0 {
+		config.Polling.Backoff.MaxInterval = time.Hour
+	}
+
+	if config.Polling.Alert != nil && config.Polling.Alert.After == 0 {
+		config.Polling.Alert.After = time.Hour
+	}
+
+	config.Delivery.Retry.ApplyDefaults()
+	for i := range config.Groups {
+		for j := range config.Groups[i].Exporters {
+			if retry := config.Groups[i].Exporters[j].Retry; retry != nil {
+				retry.ApplyDefaults()
+			}
+		}
+	}
+
 	return &config, nil
 }
 
+// ParseSourceConfig loads a single source from a YAML snippet, resolving its
+// references and validating it like the sources of a configuration file. The
+// source type defaults to rss. The source is returned as the only one of a
+// configuration holding the given group.
+func ParseSourceConfig(data []byte, group string) (*Config, error) {
+	data, secrets, err := interpolateYAML(data)
+	if err != nil {
+		return nil, err
+	}
+
+	v := viper.New()
+	v.SetConfigType("yaml")
+	if err := v.ReadConfig(bytes.NewReader(data)); err != nil {
+		return nil, fmt.Errorf("failed to read source snippet: %w", err)
+	}
+
+	source := SourceConfig{Type: SourceTypeRSS}
+	if err := v.Unmarshal(&source); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal source snippet: %w", err)
+	}
+
+	val := &validator{}
+	validateSource(val, "", &source, group, make(map[string]string))
+	if err := val.err(); err != nil {
+		return nil, err
+	}
+
+	return &Config{
+		Groups:  []GroupConfig{{Name: group, Sources: []SourceConfig{source}}},
+		secrets: secrets,
+	}, nil
+}
+
 // overrideFromEnv overrides configuration values with environment variables
 func overrideFromEnv(config *Config) {
 	// Override Redis config
@@ -61,46 +159,361 @@ func overrideFromEnv(config *Config) {
 	}
 }
 
-// ValidateConfig validates the configuration
+// ValidateConfig validates the configuration, reporting every problem found
+// as a *ValidationError
 func ValidateConfig(config *Config) error {
+	v := &validator{}
+
 	if len(config.Groups) == 0 {
-		return fmt.Errorf("no groups configured")
+		v.add("groups", "no groups configured")
 	}
 
-	for _, group := range config.Groups {
+	if config.Delivery.Workers < 0 {
+		v.add("delivery.workers", "delivery workers cannot be negative")
+	}
+	if err := validateRetry(&config.Delivery.Retry); err != nil {
+		v.add("delivery.retry", "invalid delivery retry policy: %v", err)
+	}
+
+	if config.Server.Readiness.SourceFailureThreshold < 0 {
+		v.add("server.readiness.source_failure_threshold", "readiness thresholds cannot be negative")
+	}
+	if config.Server.Readiness.ExporterFailureThreshold < 0 {
+		v.add("server.readiness.exporter_failure_threshold", "readiness thresholds cannot be negative")
+	}
+
+	if config.Polling.Backoff.Multiplier < 1 {
+		v.add("polling.backoff.multiplier", "polling backoff multiplier must be at least 1")
+	}
+	if config.Polling.Backoff.MaxInterval < 0 {
+		v.add("polling.backoff.max_interval", "polling backoff max_interval cannot be negative")
+	}
+
+	groupNames := make(map[string]bool)
+	sourceIDs := make(map[string]string)
+	exporterIDs := make(map[string]string)
+
+	for i, group := range config.Groups {
+		path := indexPath("groups", i)
+
 		if group.Name == "" {
-			return fmt.Errorf("group name cannot be empty")
+			v.add(fieldPath(path, "name"), "group name cannot be empty")
+		} else if groupNames[group.Name] {
+			v.add(fieldPath(path, "name"), "duplicate group name %s", group.Name)
+		}
+		groupNames[group.Name] = true
+
+		// With routes, a group may only provide sources or only exporters
+		if len(group.Sources) == 0 && (len(config.Routes) == 0 || len(group.Exporters) == 0) {
+			v.add(fieldPath(path, "sources"), "group %s has no sources", group.Name)
 		}
 
-		if len(group.Sources) == 0 {
-			return fmt.Errorf("group %s has no sources", group.Name)
+		if len(group.Exporters) == 0 && len(config.Routes) == 0 {
+			v.add(fieldPath(path, "exporters"), "group %s has no exporters", group.Name)
 		}
 
-		if len(group.Exporters) == 0 {
-			return fmt.Errorf("group %s has no exporters", group.Name)
+		if err := validateFilters(group.Filters); err != nil {
+			v.add(fieldPath(path, "filters"), "invalid filters: %v", err)
 		}
 
-		for _, source := range group.Sources {
-			if source.Type == "" {
-				return fmt.Errorf("source type cannot be empty in group %s", group.Name)
+		for j, source := range group.Sources {
+			validateSource(v, indexPath(fieldPath(path, "sources"), j), &source, group.Name, sourceIDs)
+		}
+
+		for j, exporter := range group.Exporters {
+			validateExporter(v, indexPath(fieldPath(path, "exporters"), j), &exporter, group.Name, exporterIDs)
+		}
+	}
+
+	if alert := config.Polling.Alert; alert != nil {
+		if _, exists := exporterIDs[alert.Exporter]; !exists {
+			v.add("polling.alert.exporter", "polling alert references unknown exporter id %s", alert.Exporter)
+		}
+		if alert.After < 0 {
+			v.add("polling.alert.after", "polling alert threshold cannot be negative")
+		}
+	}
+
+	routeNames := make(map[string]bool)
+	for i, route := range config.Routes {
+		path := indexPath("routes", i)
+
+		if route.Name == "" {
+			v.add(fieldPath(path, "name"), "route name cannot be empty")
+		} else if routeNames[route.Name] {
+			v.add(fieldPath(path, "name"), "duplicate route name %s", route.Name)
+		}
+		routeNames[route.Name] = true
+
+		if route.When == "" {
+			v.add(fieldPath(path, "when"), "route %s has no condition", route.Name)
+		}
+		if len(route.Exporters) == 0 {
+			v.add(fieldPath(path, "exporters"), "route %s has no exporters", route.Name)
+		}
+		for j, id := range route.Exporters {
+			if _, exists := exporterIDs[id]; !exists {
+				v.add(indexPath(fieldPath(path, "exporters"), j), "route %s references unknown exporter id %s", route.Name, id)
 			}
-			if source.URL == "" {
-				return fmt.Errorf("source URL cannot be empty in group %s", group.Name)
+		}
+	}
+
+	return v.err()
+}
+
+// validateSource validates a source configuration, recording its ID in ids
+func validateSource(v *validator, path string, source *SourceConfig, group string, ids map[string]string) {
+	switch source.Type {
+	case "":
+		v.add(fieldPath(path, "type"), "source type cannot be empty")
+	case SourceTypeRSS:
+	default:
+		v.add(fieldPath(path, "type"), "unknown source type: %s", source.Type)
+	}
+
+	if source.URL == "" {
+		v.add(fieldPath(path, "url"), "source URL cannot be empty")
+	} else if err := validateURL(source.URL); err != nil {
+		v.add(fieldPath(path, "url"), "invalid source URL: %v", err)
+	}
+
+	if source.Schedule != "" {
+		if source.Interval != 0 {
+			v.add(fieldPath(path, "schedule"), "source cannot have both an interval and a schedule")
+		}
+		if _, err := cron.ParseStandard(source.Schedule); err != nil {
+			v.add(fieldPath(path, "schedule"), "invalid schedule: %v", err)
+		}
+	} else if source.Interval <= 0 {
+		v.add(fieldPath(path, "interval"), "source interval must be positive")
+	}
+	if source.Jitter < 0 {
+		v.add(fieldPath(path, "jitter"), "source jitter cannot be negative")
+	}
+	if source.Adaptive != nil {
+		if source.Schedule != "" {
+			v.add(fieldPath(path, "adaptive"), "source cannot combine adaptive polling with a schedule")
+		}
+		if source.Adaptive.MinInterval <= 0 || source.Adaptive.MaxInterval < source.Adaptive.MinInterval {
+			v.add(fieldPath(path, "adaptive"), "invalid adaptive intervals")
+		}
+	}
+
+	if err := validateBackfill(source.Backfill); err != nil {
+		v.add(fieldPath(path, "backfill"), "invalid backfill: %v", err)
+	}
+
+	if err := validateFilters(source.Filters); err != nil {
+		v.add(fieldPath(path, "filters"), "invalid filters: %v", err)
+	}
+
+	id := source.ResolveID(group)
+	if other, exists := ids[id]; exists {
+		v.add(path, "duplicate source id %s (already used in group %s)", id, other)
+	}
+	ids[id] = group
+}
+
+// validateExporter validates an exporter configuration, recording its ID in ids
+func validateExporter(v *validator, path string, exporter *ExporterConfig, group string, ids map[string]string) {
+	switch exporter.Type {
+	case "":
+		v.add(fieldPath(path, "type"), "exporter type cannot be empty")
+	case ExporterTypeWebhook, ExporterTypeSlack, ExporterTypeTelegram, ExporterTypeEmail:
+	default:
+		v.add(fieldPath(path, "type"), "unknown exporter type: %s", exporter.Type)
+	}
+
+	if exporter.Target() == "" {
+		v.add(fieldPath(path, "value"), "exporter value cannot be empty")
+	} else {
+		switch exporter.Type {
+		case ExporterTypeWebhook, ExporterTypeSlack:
+			if err := validateURL(exporter.Value); err != nil {
+				v.add(fieldPath(path, "value"), "invalid webhook URL: %v", err)
 			}
-			if source.Interval == 0 {
-				return fmt.Errorf("source interval cannot be zero in group %s", group.Name)
+		case ExporterTypeEmail:
+			if _, _, err := net.SplitHostPort(exporter.Value); err != nil {
+				v.add(fieldPath(path, "value"), "invalid SMTP server address, expected host:port: %v", err)
 			}
 		}
+	}
+
+	if exporter.Type == ExporterTypeWebhook {
+		if format, ok := exporter.Options["format"]; ok {
+			switch format {
+			case WebhookFormatDiscord, WebhookFormatTeams, WebhookFormatSlack, "":
+			default:
+				v.add(fieldPath(path, "options.format"), "unknown webhook format: %v", format)
+			}
+		}
+	}
+
+	if exporter.RateLimit != nil {
+		if exporter.RateLimit.RequestsPerSecond < 0 {
+			v.add(fieldPath(path, "rate_limit.requests_per_second"), "exporter rate limit cannot be negative")
+		}
+		if exporter.RateLimit.MaxRetries != nil && *exporter.RateLimit.MaxRetries < 0 {
+			v.add(fieldPath(path, "rate_limit.max_retries"), "exporter rate limit max_retries cannot be negative")
+		}
+	}
+
+	if exporter.Retry != nil {
+		if err := validateRetry(exporter.Retry); err != nil {
+			v.add(fieldPath(path, "retry"), "invalid retry policy: %v", err)
+		}
+	}
+
+	if exporter.Digest != nil {
+		if err := validateDigest(exporter.Digest); err != nil {
+			v.add(fieldPath(path, "digest"), "invalid digest: %v", err)
+		}
+	}
+
+	if exporter.Window != nil {
+		if err := validateWindow(exporter.Window); err != nil {
+			v.add(fieldPath(path, "window"), "invalid window: %v", err)
+		}
+	}
+
+	if err := validateFilters(exporter.Filters); err != nil {
+		v.add(fieldPath(path, "filters"), "invalid filters: %v", err)
+	}
+
+	id := exporter.ResolveID(group)
+	if other, exists := ids[id]; exists {
+		v.add(path, "duplicate exporter id %s (already used in group %s)", id, other)
+	}
+	ids[id] = group
+}
+
+// validateURL checks that a URL is absolute with an HTTP(S) scheme and a host
+func validateURL(rawURL string) error {
+	parsedURL, err := url.Parse(rawURL)
+	if err != nil {
+		return err
+	}
+	if parsedURL.Scheme != "http" && parsedURL.Scheme != "https" {
+		return fmt.Errorf("scheme must be http or https: %s", rawURL)
+	}
+	if parsedURL.Host == "" {
+		return fmt.Errorf("missing host: %s", rawURL)
+	}
+	return nil
+}
+
+// validateBackfill validates a source backfill configuration
+func validateBackfill(backfill *BackfillConfig) error {
+	if backfill == nil {
+		return nil
+	}
+
+	switch backfill.Mode {
+	case "", BackfillAll, BackfillNone:
+	case BackfillLast:
+		if backfill.Count <= 0 {
+			return fmt.Errorf("count must be positive for mode %s", backfill.Mode)
+		}
+	case BackfillSince:
+		if backfill.Since <= 0 {
+			return fmt.Errorf("since must be positive for mode %s", backfill.Mode)
+		}
+	default:
+		return fmt.Errorf("unknown mode: %s", backfill.Mode)
+	}
+
+	return nil
+}
+
+// validateFilters validates a filter configuration
+func validateFilters(filters *FilterConfig) error {
+	if filters == nil {
+		return nil
+	}
+
+	if filters.MaxAge < 0 {
+		return fmt.Errorf("max_age cannot be negative")
+	}
 
-		for _, exporter := range group.Exporters {
-			if exporter.Type == "" {
-				return fmt.Errorf("exporter type cannot be empty in group %s", group.Name)
+	rules := append(append([]FilterRule{}, filters.Include...), filters.Exclude...)
+	for _, rule := range rules {
+		if len(rule.Keywords) == 0 && rule.Pattern == "" {
+			return fmt.Errorf("rule must have keywords or a pattern")
+		}
+		if rule.Pattern != "" {
+			if _, err := regexp.Compile(rule.Pattern); err != nil {
+				return fmt.Errorf("invalid pattern %q: %w", rule.Pattern, err)
 			}
-			if exporter.Value == "" {
-				return fmt.Errorf("exporter value cannot be empty in group %s", group.Name)
+		}
+		for _, field := range rule.Fields {
+			switch field {
+			case FilterFieldTitle, FilterFieldDescription, FilterFieldLink, FilterFieldCategories:
+			default:
+				return fmt.Errorf("unknown field: %s", field)
 			}
 		}
 	}
 
 	return nil
-} 
\ No newline at end of file
+}
+
+// validateDigest validates an exporter digest configuration
+func validateDigest(digest *DigestConfig) error {
+	if digest.Interval <= 0 {
+		return fmt.Errorf("interval must be positive")
+	}
+	if digest.MaxItems < 0 {
+		return fmt.Errorf("max_items cannot be negative")
+	}
+	return nil
+}
+
+// validateWindow validates an exporter delivery window
+func validateWindow(window *WindowConfig) error {
+	if _, err := window.Location(); err != nil {
+		return err
+	}
+
+	if len(window.Periods) == 0 {
+		return fmt.Errorf("at least one period is required")
+	}
+
+	for _, period := range window.Periods {
+		for _, day := range period.Days {
+			if _, err := ParseWeekday(day); err != nil {
+				return err
+			}
+		}
+
+		start, err := ParseClock(period.Start)
+		if err != nil {
+			return err
+		}
+		end, err := ParseClock(period.End)
+		if err != nil {
+			return err
+		}
+		if start == end {
+			return fmt.Errorf("period start and end cannot be equal: %s", period.Start)
+		}
+	}
+
+	return nil
+}
+
+// validateRetry validates a retry policy
+func validateRetry(retry *RetryConfig) error {
+	if retry.MaxAttempts < 1 {
+		return fmt.Errorf("max_attempts must be at least 1")
+	}
+	if retry.InitialBackoff < 0 || retry.MaxBackoff < 0 {
+		return fmt.Errorf("backoff durations cannot be negative")
+	}
+	if retry.Multiplier < 1 {
+		return fmt.Errorf("multiplier must be at least 1")
+	}
+	if retry.Jitter != nil && (*retry.Jitter < 0 || *retry.Jitter > 1) {
+		return fmt.Errorf("jitter must be between 0 and 1")
+	}
+	return nil
+}
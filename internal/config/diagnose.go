@@ -0,0 +1,219 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"reflect"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"gopkg.in/yaml.v3"
+)
+
+// yamlLinePattern extracts the line number of a YAML syntax error
+var yamlLinePattern = regexp.MustCompile(`line (\d+)`)
+
+// pathSegmentPattern splits a field path segment into its key and indexes
+var pathSegmentPattern = regexp.MustCompile(`^([^\[]*)((?:\[\d+\])*)$`)
+
+// Diagnostics are the problems found in a configuration file, located in the
+// YAML document
+type Diagnostics struct {
+	// Config is the loaded configuration, nil when the file could not be loaded
+	Config   *Config
+	Problems []Problem
+	root     *yaml.Node
+}
+
+// Diagnose loads a configuration file and reports every problem found in it:
+// YAML syntax errors, unknown keys and validation errors. An error is only
+// returned when the file cannot be read.
+func Diagnose(path string) (*Diagnostics, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read config file: %w", err)
+	}
+
+	d := &Diagnostics{}
+
+	var document yaml.Node
+	if err := yaml.Unmarshal(data, &document); err != nil {
+		problem := Problem{Message: err.Error()}
+		if match := yamlLinePattern.FindStringSubmatch(err.Error()); match != nil {
+			problem.Line, _ = strconv.Atoi(match[1])
+		}
+		d.Problems = append(d.Problems, problem)
+		return d, nil
+	}
+	if len(document.Content) > 0 {
+		d.root = document.Content[0]
+		d.checkKeys(d.root, reflect.TypeOf(Config{}), "")
+	}
+
+	cfg, err := ParseConfig(data)
+	if err != nil {
+		var validationErr *ValidationError
+		if !errors.As(err, &validationErr) {
+			d.Add("", "%v", err)
+			return d, nil
+		}
+		// Unresolved references, already located
+		d.Problems = append(d.Problems, validationErr.Problems...)
+		return d, nil
+	}
+	d.Config = cfg
+
+	if err := ValidateConfig(cfg); err != nil {
+		var validationErr *ValidationError
+		if !errors.As(err, &validationErr) {
+			d.Add("", "%v", err)
+			return d, nil
+		}
+		for _, problem := range validationErr.Problems {
+			d.Add(problem.Path, "%s", problem.Message)
+		}
+	}
+
+	return d, nil
+}
+
+// Add records a problem at a field path, located at the closest field of the
+// path present in the YAML document. Problems are kept in document order.
+func (d *Diagnostics) Add(path, format string, args ...interface{}) {
+	problem := Problem{
+		Path:    path,
+		Message: fmt.Sprintf(format, args...),
+	}
+	if node := d.locate(path); node != nil {
+		problem.Line = node.Line
+		problem.Column = node.Column
+	}
+
+	d.Problems = append(d.Problems, problem)
+	sort.SliceStable(d.Problems, func(i, j int) bool {
+		if d.Problems[i].Line != d.Problems[j].Line {
+			return d.Problems[i].Line < d.Problems[j].Line
+		}
+		return d.Problems[i].Column < d.Problems[j].Column
+	})
+}
+
+// checkKeys reports the keys of a YAML mapping that are not fields of the
+// configuration type, recursing into nested structures
+func (d *Diagnostics) checkKeys(node *yaml.Node, t reflect.Type, path string) {
+	node = resolveAlias(node)
+	for t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	if t == reflect.TypeOf(time.Duration(0)) {
+		return
+	}
+
+	switch t.Kind() {
+	case reflect.Struct:
+		if node.Kind != yaml.MappingNode {
+			return
+		}
+		for i := 0; i+1 < len(node.Content); i += 2 {
+			key, value := node.Content[i], node.Content[i+1]
+			field, ok := yamlField(t, key.Value)
+			if !ok {
+				d.Problems = append(d.Problems, Problem{
+					Path:    fieldPath(path, key.Value),
+					Line:    key.Line,
+					Column:  key.Column,
+					Message: fmt.Sprintf("unknown field %s", key.Value),
+				})
+				continue
+			}
+			d.checkKeys(value, field.Type, fieldPath(path, key.Value))
+		}
+	case reflect.Slice:
+		if node.Kind != yaml.SequenceNode {
+			return
+		}
+		for i, item := range node.Content {
+			d.checkKeys(item, t.Elem(), indexPath(path, i))
+		}
+	}
+}
+
+// locate returns the YAML node of the closest field of a path present in
+// the document: the key of a mapping entry or the item of a sequence
+func (d *Diagnostics) locate(path string) *yaml.Node {
+	if d.root == nil || path == "" {
+		return nil
+	}
+
+	var located *yaml.Node
+	node := d.root
+	for _, segment := range strings.Split(path, ".") {
+		match := pathSegmentPattern.FindStringSubmatch(segment)
+		if match == nil {
+			return located
+		}
+
+		if match[1] != "" {
+			key, value := mappingEntry(node, match[1])
+			if key == nil {
+				return located
+			}
+			located, node = key, value
+		}
+
+		for _, index := range strings.Split(strings.Trim(match[2], "[]"), "][") {
+			if index == "" {
+				continue
+			}
+			i, _ := strconv.Atoi(index)
+			node = resolveAlias(node)
+			if node.Kind != yaml.SequenceNode || i >= len(node.Content) {
+				return located
+			}
+			node = node.Content[i]
+			located = node
+		}
+	}
+
+	return located
+}
+
+// mappingEntry returns the key and value nodes of a mapping entry, matching
+// the key case-insensitively like the configuration loader
+func mappingEntry(node *yaml.Node, name string) (*yaml.Node, *yaml.Node) {
+	node = resolveAlias(node)
+	if node.Kind != yaml.MappingNode {
+		return nil, nil
+	}
+	for i := 0; i+1 < len(node.Content); i += 2 {
+		if strings.EqualFold(node.Content[i].Value, name) {
+			return node.Content[i], node.Content[i+1]
+		}
+	}
+	return nil, nil
+}
+
+// yamlField returns the struct field with the given YAML key, matched
+// case-insensitively like the configuration loader
+func yamlField(t reflect.Type, key string) (reflect.StructField, bool) {
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		name := strings.Split(field.Tag.Get("yaml"), ",")[0]
+		if name != "" && strings.EqualFold(name, key) {
+			return field, true
+		}
+	}
+	return reflect.StructField{}, false
+}
+
+// resolveAlias returns the node referenced by an alias node
+func resolveAlias(node *yaml.Node) *yaml.Node {
+	for node.Kind == yaml.AliasNode && node.Alias != nil {
+		node = node.Alias
+	}
+	return node
+}
@@ -0,0 +1,59 @@
+package config
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+)
+
+// ResolveID returns the explicit source ID if configured, otherwise a stable
+// identifier derived from the group name, source type and URL
+func (c *SourceConfig) ResolveID(group string) string {
+	if c.ID != "" {
+		return c.ID
+	}
+	return hashID(group, c.Type, c.URL)
+}
+
+// identityOptions are the exporter options that address a distinct
+// destination, and therefore take part in the derived exporter ID
+var identityOptions = []string{"chat_id", "to"}
+
+// ResolveID returns the explicit exporter ID if configured, otherwise a stable
+// identifier derived from the group name, exporter type, value and the
+// options addressing the destination
+func (c *ExporterConfig) ResolveID(group string) string {
+	if c.ID != "" {
+		return c.ID
+	}
+
+	parts := []string{group, c.Type, c.Value}
+	for _, key := range identityOptions {
+		if value, ok := c.Options[key]; ok && value != nil {
+			parts = append(parts, key, fmt.Sprint(value))
+		}
+	}
+	return hashID(parts...)
+}
+
+// Target returns the destination of the exporter: its value, or the chat_id
+// option for exporters addressed through options (e.g. telegram)
+func (c *ExporterConfig) Target() string {
+	if c.Value != "" {
+		return c.Value
+	}
+	if chatID, ok := c.Options["chat_id"]; ok && chatID != nil {
+		return fmt.Sprint(chatID)
+	}
+	return ""
+}
+
+// hashID builds a short, stable identifier from the given parts
+func hashID(parts ...string) string {
+	h := sha256.New()
+	for _, part := range parts {
+		h.Write([]byte(part))
+		h.Write([]byte{0})
+	}
+	return hex.EncodeToString(h.Sum(nil))[:16]
+}
@@ -0,0 +1,215 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"regexp"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// referencePattern matches ${VAR}, ${VAR:-default} and ${file:/path}
+// references, the $${ escape of a literal "${", and any other "${" as an
+// invalid reference
+var referencePattern = regexp.MustCompile(`\$\$\{|\$\{file:([^}]+)\}|\$\{([A-Za-z_][A-Za-z0-9_]*)(:-([^}]*))?\}|\$\{[^}]*\}?`)
+
+// referenceSyntax describes the supported references in errors
+const referenceSyntax = "expected ${VAR}, ${VAR:-default} or ${file:/path}, or $${ for a literal ${"
+
+// secretTag marks a value to redact from the logs, whatever its field
+const secretTag = "!secret"
+
+// secretKeys are the keys of the fields holding credentials, whose values
+// are redacted from the logs
+var secretKeys = map[string]bool{
+	"password":            true,
+	"secret":              true,
+	"token":               true,
+	"authorization":       true,
+	"proxy-authorization": true,
+	"cookie":              true,
+}
+
+// secretKeySuffixes mark the keys of credentials such as bot_token or x-api-key
+var secretKeySuffixes = []string{"_password", "_secret", "_token", "-token", "api_key", "api-key", "apikey"}
+
+// exporterPathPattern matches the path of an exporter, whose value and
+// options often embed credentials such as webhook URLs or chat IDs
+var exporterPathPattern = regexp.MustCompile(`(^|\.)exporters\[\d+\]$`)
+
+// redaction is how much of a resolved value is redacted from the logs
+type redaction int
+
+const (
+	redactNone redaction = iota
+	// redactReferences redacts the resolved references of a value
+	redactReferences
+	// redactValue redacts the whole value and its resolved references
+	redactValue
+)
+
+// minSecretLength is the length under which values are not redacted from the
+// logs, as they would mask unrelated text
+const minSecretLength = 4
+
+// interpolator resolves the references of a YAML document, collecting the
+// values of credential fields and the references of exporter targets as secrets
+type interpolator struct {
+	problems []Problem
+	secrets  []string
+}
+
+// interpolateYAML resolves the environment and file references of every
+// string value of a YAML document. Unresolved references are reported as a
+// *ValidationError.
+func interpolateYAML(data []byte) ([]byte, []string, error) {
+	var document yaml.Node
+	if err := yaml.Unmarshal(data, &document); err != nil {
+		return nil, nil, fmt.Errorf("failed to parse config file: %w", err)
+	}
+	if document.Kind == 0 {
+		return data, nil, nil
+	}
+
+	i := &interpolator{}
+	i.node(&document, "", redactNone)
+	if len(i.problems) > 0 {
+		return nil, nil, &ValidationError{Problems: i.problems}
+	}
+
+	interpolated, err := yaml.Marshal(&document)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to encode interpolated config: %w", err)
+	}
+	return interpolated, i.secrets, nil
+}
+
+// node resolves the references of the string scalars under a node. The
+// values under a credential field, and the references under the value and
+// options of an exporter, are recorded as secrets.
+func (i *interpolator) node(node *yaml.Node, path string, redact redaction) {
+	switch node.Kind {
+	case yaml.DocumentNode:
+		for _, child := range node.Content {
+			i.node(child, path, redact)
+		}
+	case yaml.MappingNode:
+		for j := 0; j+1 < len(node.Content); j += 2 {
+			key := node.Content[j].Value
+			i.node(node.Content[j+1], fieldPath(path, key), max(redact, keyRedaction(path, key)))
+		}
+	case yaml.SequenceNode:
+		for j, child := range node.Content {
+			i.node(child, indexPath(path, j), redact)
+		}
+	case yaml.ScalarNode:
+		if node.Tag == secretTag {
+			node.Tag = "!!str"
+			redact = redactValue
+		}
+		if node.Tag != "!!str" {
+			return
+		}
+		resolved, references, err := resolveReferences(node.Value)
+		if err != nil {
+			i.problems = append(i.problems, Problem{
+				Path:    path,
+				Line:    node.Line,
+				Column:  node.Column,
+				Message: err.Error(),
+			})
+			return
+		}
+		node.Value = resolved
+
+		// References are also redacted alone, e.g. the token of a header
+		var secrets []string
+		switch redact {
+		case redactReferences:
+			secrets = references
+		case redactValue:
+			secrets = append(references, resolved)
+		}
+		for _, value := range secrets {
+			if len(value) >= minSecretLength {
+				i.secrets = append(i.secrets, value)
+			}
+		}
+	}
+}
+
+// keyRedaction returns the redaction of the value of a key under a path
+func keyRedaction(path, key string) redaction {
+	if isSecretKey(key) {
+		return redactValue
+	}
+	if (key == "value" || key == "options") && exporterPathPattern.MatchString(path) {
+		return redactReferences
+	}
+	return redactNone
+}
+
+// isSecretKey reports whether a key names a credential field
+func isSecretKey(key string) bool {
+	key = strings.ToLower(key)
+	if secretKeys[key] {
+		return true
+	}
+	for _, suffix := range secretKeySuffixes {
+		if strings.HasSuffix(key, suffix) {
+			return true
+		}
+	}
+	return false
+}
+
+// resolveReferences returns a value with its ${VAR} references replaced by
+// the variables and its ${file:/path} references by the file contents, and
+// the values of the references
+func resolveReferences(value string) (string, []string, error) {
+	var references []string
+	var errs []string
+	var missing []string
+	resolved := referencePattern.ReplaceAllStringFunc(value, func(reference string) string {
+		if reference == "$${" {
+			return "${"
+		}
+
+		match := referencePattern.FindStringSubmatch(reference)
+		if path := match[1]; path != "" {
+			data, err := os.ReadFile(path)
+			if err != nil {
+				errs = append(errs, fmt.Sprintf("failed to read referenced file: path=%s error=%v", path, err))
+				return ""
+			}
+			// Mounted secrets commonly end with a newline
+			content := strings.TrimRight(string(data), "\r\n")
+			references = append(references, content)
+			return content
+		}
+
+		if match[2] == "" {
+			errs = append(errs, fmt.Sprintf("invalid reference %q, %s", reference, referenceSyntax))
+			return ""
+		}
+
+		if env, ok := os.LookupEnv(match[2]); ok && env != "" {
+			references = append(references, env)
+			return env
+		}
+		if match[3] != "" {
+			return match[4]
+		}
+		missing = append(missing, match[2])
+		return ""
+	})
+	if len(missing) > 0 {
+		errs = append(errs, fmt.Sprintf("unset environment variables: %s", strings.Join(missing, ", ")))
+	}
+	if len(errs) > 0 {
+		return "", nil, errors.New(strings.Join(errs, "; "))
+	}
+	return resolved, references, nil
+}
@@ -0,0 +1,182 @@
+package config
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"gopkg.in/yaml.v3"
+)
+
+func TestInterpolateYAML(t *testing.T) {
+	t.Setenv("BRIDGR_TEST_HOST", "feeds.example.com")
+	t.Setenv("BRIDGR_TEST_TOKEN", "s3cr3t-token")
+	t.Setenv("BRIDGR_TEST_PATH", "T000/B000/XXXX")
+	t.Setenv("BRIDGR_TEST_EMPTY", "")
+
+	tokenFile := filepath.Join(t.TempDir(), "token")
+	if err := os.WriteFile(tokenFile, []byte("file-token\n"), 0o600); err != nil {
+		t.Fatalf("failed to write token file: %v", err)
+	}
+
+	tests := []struct {
+		name    string
+		input   string
+		want    map[string]interface{}
+		secrets []string
+	}{
+		{
+			name:  "environment variable",
+			input: `url: "https://${BRIDGR_TEST_HOST}/feed.xml"`,
+			want:  map[string]interface{}{"url": "https://feeds.example.com/feed.xml"},
+		},
+		{
+			name:  "default of unset variable",
+			input: `url: "${BRIDGR_TEST_UNSET:-https://example.com/feed.xml}"`,
+			want:  map[string]interface{}{"url": "https://example.com/feed.xml"},
+		},
+		{
+			name:  "default of empty variable",
+			input: `url: "${BRIDGR_TEST_EMPTY:-fallback}"`,
+			want:  map[string]interface{}{"url": "fallback"},
+		},
+		{
+			name:  "default of set variable",
+			input: `host: "${BRIDGR_TEST_HOST:-fallback}"`,
+			want:  map[string]interface{}{"host": "feeds.example.com"},
+		},
+		{
+			name:    "file reference without trailing newline",
+			input:   "bot_token: \"${file:" + tokenFile + "}\"",
+			want:    map[string]interface{}{"bot_token": "file-token"},
+			secrets: []string{"file-token", "file-token"},
+		},
+		{
+			name:  "bare file value",
+			input: `url: "file:/etc/bridgr/feed.xml"`,
+			want:  map[string]interface{}{"url": "file:/etc/bridgr/feed.xml"},
+		},
+		{
+			name:  "escaped reference",
+			input: `template: "$${BRIDGR_TEST_HOST}"`,
+			want:  map[string]interface{}{"template": "${BRIDGR_TEST_HOST}"},
+		},
+		{
+			name:  "non-string values are kept",
+			input: "port: 8080\nenabled: true",
+			want:  map[string]interface{}{"port": 8080, "enabled": true},
+		},
+		{
+			name:    "credential field",
+			input:   `password: "prefix-${BRIDGR_TEST_TOKEN}"`,
+			want:    map[string]interface{}{"password": "prefix-s3cr3t-token"},
+			secrets: []string{"s3cr3t-token", "prefix-s3cr3t-token"},
+		},
+		{
+			name:    "credential header",
+			input:   "headers:\n  Authorization: \"Bearer ${BRIDGR_TEST_TOKEN}\"",
+			want:    map[string]interface{}{"headers": map[string]interface{}{"Authorization": "Bearer s3cr3t-token"}},
+			secrets: []string{"s3cr3t-token", "Bearer s3cr3t-token"},
+		},
+		{
+			name:    "secret tag",
+			input:   `value: !secret "https://hooks.example.com/literal"`,
+			want:    map[string]interface{}{"value": "https://hooks.example.com/literal"},
+			secrets: []string{"https://hooks.example.com/literal"},
+		},
+		{
+			name:    "exporter value reference",
+			input:   "exporters:\n  - value: \"https://hooks.example.com/${BRIDGR_TEST_PATH}\"",
+			want:    map[string]interface{}{"exporters": []interface{}{map[string]interface{}{"value": "https://hooks.example.com/T000/B000/XXXX"}}},
+			secrets: []string{"T000/B000/XXXX"},
+		},
+		{
+			name:    "exporter option reference",
+			input:   "exporters:\n  - options:\n      chat: \"${BRIDGR_TEST_PATH}\"",
+			want:    map[string]interface{}{"exporters": []interface{}{map[string]interface{}{"options": map[string]interface{}{"chat": "T000/B000/XXXX"}}}},
+			secrets: []string{"T000/B000/XXXX"},
+		},
+		{
+			name:  "source reference is not redacted",
+			input: "sources:\n  - url: \"https://${BRIDGR_TEST_HOST}/feed.xml\"",
+			want:  map[string]interface{}{"sources": []interface{}{map[string]interface{}{"url": "https://feeds.example.com/feed.xml"}}},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			data, secrets, err := interpolateYAML([]byte(tt.input))
+			if err != nil {
+				t.Fatalf("interpolateYAML() error = %v", err)
+			}
+
+			var got map[string]interface{}
+			if err := yaml.Unmarshal(data, &got); err != nil {
+				t.Fatalf("failed to parse interpolated YAML: %v", err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("interpolateYAML() = %v, want %v", got, tt.want)
+			}
+			if !reflect.DeepEqual(secrets, tt.secrets) {
+				t.Errorf("interpolateYAML() secrets = %q, want %q", secrets, tt.secrets)
+			}
+		})
+	}
+}
+
+func TestInterpolateYAMLUnresolved(t *testing.T) {
+	tests := []struct {
+		name    string
+		input   string
+		path    string
+		line    int
+		message string
+	}{
+		{
+			name:    "unset variable",
+			input:   "redis:\n  address: \"${BRIDGR_TEST_UNSET}\"",
+			path:    "redis.address",
+			line:    2,
+			message: "unset environment variables: BRIDGR_TEST_UNSET",
+		},
+		{
+			name:    "missing file",
+			input:   "token: \"${file:/nonexistent/bridgr/token}\"",
+			path:    "token",
+			line:    1,
+			message: "failed to read referenced file: path=/nonexistent/bridgr/token",
+		},
+		{
+			name:    "invalid reference",
+			input:   "groups:\n  - name: \"${file:}\"",
+			path:    "groups[0].name",
+			line:    2,
+			message: `invalid reference "${file:}"`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, _, err := interpolateYAML([]byte(tt.input))
+
+			var validationErr *ValidationError
+			if !errors.As(err, &validationErr) {
+				t.Fatalf("interpolateYAML() error = %v, want a *ValidationError", err)
+			}
+			if len(validationErr.Problems) != 1 {
+				t.Fatalf("interpolateYAML() problems = %v, want 1", validationErr.Problems)
+			}
+
+			problem := validationErr.Problems[0]
+			if problem.Path != tt.path || problem.Line != tt.line {
+				t.Errorf("problem at %s line %d, want %s line %d", problem.Path, problem.Line, tt.path, tt.line)
+			}
+			if !strings.HasPrefix(problem.Message, tt.message) {
+				t.Errorf("problem message = %q, want prefix %q", problem.Message, tt.message)
+			}
+		})
+	}
+}
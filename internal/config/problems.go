@@ -0,0 +1,80 @@
+package config
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Problem is an error found in a configuration, at a field path such as
+// "groups[0].sources[1].url". Line and Column locate the field in the YAML
+// file when known, and are zero otherwise.
+type Problem struct {
+	Path    string `json:"path,omitempty"`
+	Line    int    `json:"line,omitempty"`
+	Column  int    `json:"column,omitempty"`
+	Message string `json:"message"`
+}
+
+// String formats the problem as "line:column: path: message"
+func (p Problem) String() string {
+	var b strings.Builder
+	switch {
+	case p.Line > 0 && p.Column > 0:
+		fmt.Fprintf(&b, "%d:%d: ", p.Line, p.Column)
+	case p.Line > 0:
+		fmt.Fprintf(&b, "%d: ", p.Line)
+	}
+	if p.Path != "" {
+		fmt.Fprintf(&b, "%s: ", p.Path)
+	}
+	b.WriteString(p.Message)
+	return b.String()
+}
+
+// ValidationError lists every problem found in a configuration
+type ValidationError struct {
+	Problems []Problem
+}
+
+// Error joins the problems into a single message
+func (e *ValidationError) Error() string {
+	messages := make([]string, 0, len(e.Problems))
+	for _, problem := range e.Problems {
+		messages = append(messages, problem.String())
+	}
+	return strings.Join(messages, "; ")
+}
+
+// validator collects the problems of a configuration
+type validator struct {
+	problems []Problem
+}
+
+// add records a problem at a field path
+func (v *validator) add(path, format string, args ...interface{}) {
+	v.problems = append(v.problems, Problem{
+		Path:    path,
+		Message: fmt.Sprintf(format, args...),
+	})
+}
+
+// err returns the collected problems as a ValidationError, or nil without problems
+func (v *validator) err() error {
+	if len(v.problems) == 0 {
+		return nil
+	}
+	return &ValidationError{Problems: v.problems}
+}
+
+// fieldPath joins a field path and a field name
+func fieldPath(path, field string) string {
+	if path == "" {
+		return field
+	}
+	return path + "." + field
+}
+
+// indexPath appends an index to a field path
+func indexPath(path string, index int) string {
+	return fmt.Sprintf("%s[%d]", path, index)
+}
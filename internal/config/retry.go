@@ -0,0 +1,44 @@
+package config
+
+import "time"
+
+// DefaultRetryableStatusCodes are the HTTP status codes retried when a policy
+// does not list its own
+var DefaultRetryableStatusCodes = []int{408, 425, 429, 500, 502, 503, 504}
+
+// DefaultRetryJitter is the jitter applied when a policy does not set one
+const DefaultRetryJitter = 0.2
+
+// ApplyDefaults fills the unset fields of the retry policy
+func (r *RetryConfig) ApplyDefaults() {
+	if r.MaxAttempts == 0 {
+		r.MaxAttempts = 5
+	}
+	if r.InitialBackoff == 0 {
+		r.InitialBackoff = time.Second
+	}
+	if r.MaxBackoff == 0 {
+		r.MaxBackoff = 5 * time.Minute
+	}
+	if r.Multiplier == 0 {
+		r.Multiplier = 2
+	}
+	// Jitter is a pointer so that 0 can disable it
+	if r.Jitter == nil {
+		jitter := DefaultRetryJitter
+		r.Jitter = &jitter
+	}
+	if len(r.RetryableStatusCodes) == 0 {
+		r.RetryableStatusCodes = DefaultRetryableStatusCodes
+	}
+}
+
+// IsRetryableStatus reports whether an HTTP status code should be retried
+func (r *RetryConfig) IsRetryableStatus(statusCode int) bool {
+	for _, code := range r.RetryableStatusCodes {
+		if code == statusCode {
+			return true
+		}
+	}
+	return false
+}
@@ -4,9 +4,48 @@ import "time"
 
 // Config represents the root configuration structure
 type Config struct {
-	Groups []GroupConfig `yaml:"groups"`
-	Redis  RedisConfig   `yaml:"redis"`
-	Server ServerConfig  `yaml:"server"`
+	Groups   []GroupConfig  `yaml:"groups"`
+	Redis    RedisConfig    `yaml:"redis"`
+	Server   ServerConfig   `yaml:"server"`
+	Delivery DeliveryConfig `yaml:"delivery"`
+	Polling  PollingConfig  `yaml:"polling"`
+	Routes   []RouteConfig  `yaml:"routes,omitempty"`
+
+	// secrets are the values of the credential fields and of the values
+	// tagged !secret
+	secrets []string
+}
+
+// Secrets returns the values of the credential fields and of the values
+// tagged !secret, to be redacted from the logs
+func (c *Config) Secrets() []string {
+	return c.secrets
+}
+
+// PollingConfig controls how failing sources are retried and reported
+type PollingConfig struct {
+	Backoff BackoffConfig `yaml:"backoff"`
+	Alert   *AlertConfig  `yaml:"alert,omitempty"`
+}
+
+// BackoffConfig slows down the polling of a source after consecutive failures
+type BackoffConfig struct {
+	Multiplier  float64       `yaml:"multiplier"`
+	MaxInterval time.Duration `yaml:"max_interval" mapstructure:"max_interval"`
+}
+
+// AlertConfig sends a notification through an admin exporter when a source
+// has been failing for longer than the threshold, and when it recovers
+type AlertConfig struct {
+	Exporter string        `yaml:"exporter"`
+	After    time.Duration `yaml:"after"`
+}
+
+// RouteConfig delivers the items matching an expression to exporters of any group
+type RouteConfig struct {
+	Name      string   `yaml:"name"`
+	When      string   `yaml:"when"`
+	Exporters []string `yaml:"exporters"`
 }
 
 // GroupConfig represents a group configuration
@@ -14,27 +53,151 @@ type GroupConfig struct {
 	Name      string           `yaml:"name"`
 	Sources   []SourceConfig   `yaml:"sources"`
 	Exporters []ExporterConfig `yaml:"exporters"`
+	Filters   *FilterConfig    `yaml:"filters,omitempty"`
 }
 
 // SourceConfig represents a source configuration
 type SourceConfig struct {
+	ID       string        `yaml:"id,omitempty"`
 	Type     string        `yaml:"type"`
 	URL      string        `yaml:"url"`
-	Interval time.Duration `yaml:"interval"`
-	TTL      time.Duration `yaml:"ttl,omitempty"`
+	Interval time.Duration   `yaml:"interval"`
+	Schedule string          `yaml:"schedule,omitempty"`
+	Jitter   time.Duration   `yaml:"jitter,omitempty"`
+	Adaptive *AdaptiveConfig `yaml:"adaptive,omitempty"`
+	TTL      time.Duration   `yaml:"ttl,omitempty"`
+	Backfill *BackfillConfig `yaml:"backfill,omitempty"`
+	Filters  *FilterConfig   `yaml:"filters,omitempty"`
+}
+
+// Source types
+const (
+	SourceTypeRSS = "rss"
+)
+
+// AdaptiveConfig adjusts the polling interval of a source to the observed
+// publish frequency of its items, within bounds
+type AdaptiveConfig struct {
+	MinInterval time.Duration `yaml:"min_interval" mapstructure:"min_interval"`
+	MaxInterval time.Duration `yaml:"max_interval" mapstructure:"max_interval"`
+}
+
+// Backfill modes
+const (
+	BackfillAll   = "all"
+	BackfillNone  = "none"
+	BackfillLast  = "last"
+	BackfillSince = "since"
+)
+
+// BackfillConfig controls which items are exported on the first poll of a new source
+type BackfillConfig struct {
+	Mode  string        `yaml:"mode"`
+	Count int           `yaml:"count,omitempty"`
+	Since time.Duration `yaml:"since,omitempty"`
+}
+
+// Filter fields
+const (
+	FilterFieldTitle       = "title"
+	FilterFieldDescription = "description"
+	FilterFieldLink        = "link"
+	FilterFieldCategories  = "categories"
+)
+
+// FilterConfig selects the items forwarded to exporters. An item passes if it
+// matches at least one include rule (when any), no exclude rule, and is not
+// older than max_age.
+type FilterConfig struct {
+	Include []FilterRule  `yaml:"include,omitempty"`
+	Exclude []FilterRule  `yaml:"exclude,omitempty"`
+	MaxAge  time.Duration `yaml:"max_age,omitempty" mapstructure:"max_age"`
 }
 
+// FilterRule matches items containing any of the keywords (case-insensitive)
+// or matching the pattern in one of the fields (title and description by default)
+type FilterRule struct {
+	Keywords []string `yaml:"keywords,omitempty"`
+	Pattern  string   `yaml:"pattern,omitempty"`
+	Fields   []string `yaml:"fields,omitempty"`
+}
+
+// Exporter types
+const (
+	ExporterTypeWebhook  = "webhook"
+	ExporterTypeSlack    = "slack"
+	ExporterTypeTelegram = "telegram"
+	ExporterTypeEmail    = "email"
+)
+
+// Webhook payload formats, set with the format option of webhook exporters
+const (
+	WebhookFormatDiscord = "discord"
+	WebhookFormatTeams   = "teams"
+	WebhookFormatSlack   = "slack"
+)
+
 // ExporterConfig represents an exporter configuration
 type ExporterConfig struct {
+	ID         string                 `yaml:"id,omitempty"`
 	Type       string                 `yaml:"type"`
 	Value      string                 `yaml:"value"`
 	Options    map[string]interface{} `yaml:"options"`
-	RateLimit  *RateLimitConfig      `yaml:"rate_limit,omitempty"`
+	RateLimit  *RateLimitConfig      `yaml:"rate_limit,omitempty" mapstructure:"rate_limit"`
+	Retry      *RetryConfig          `yaml:"retry,omitempty"`
+	Filters    *FilterConfig         `yaml:"filters,omitempty"`
+	When       string                `yaml:"when,omitempty"`
+	Digest     *DigestConfig         `yaml:"digest,omitempty"`
+	Window     *WindowConfig         `yaml:"window,omitempty"`
+}
+
+// WindowConfig restricts the deliveries of an exporter to periods of the week.
+// Items arriving outside the window are held and released when it opens,
+// as a single digest if collapse is set.
+type WindowConfig struct {
+	Timezone string         `yaml:"timezone,omitempty"`
+	Periods  []WindowPeriod `yaml:"periods"`
+	Collapse bool           `yaml:"collapse,omitempty"`
+}
+
+// WindowPeriod is a daily time range ("08:00" to "19:00") on the given days
+// (every day if empty). A range ending before it starts spans midnight.
+type WindowPeriod struct {
+	Days  []string `yaml:"days,omitempty"`
+	Start string   `yaml:"start"`
+	End   string   `yaml:"end"`
+}
+
+// DigestConfig buffers the items of an exporter and sends them as a single
+// summarized notification every interval, or as soon as max_items are buffered
+type DigestConfig struct {
+	Interval time.Duration `yaml:"interval"`
+	MaxItems int           `yaml:"max_items,omitempty" mapstructure:"max_items"`
 }
 
 // RateLimitConfig represents rate limiting configuration
 type RateLimitConfig struct {
-	RequestsPerSecond float64 `yaml:"requests_per_second"`
+	RequestsPerSecond float64 `yaml:"requests_per_second" mapstructure:"requests_per_second"`
+	// MaxRetries is a pointer so that 0 can disable the 429 retries
+	MaxRetries *int `yaml:"max_retries,omitempty" mapstructure:"max_retries"`
+}
+
+// DeliveryConfig represents the delivery queue configuration
+type DeliveryConfig struct {
+	Workers      int           `yaml:"workers"`
+	QueueSize    int           `yaml:"queue_size" mapstructure:"queue_size"`
+	DrainTimeout time.Duration `yaml:"drain_timeout" mapstructure:"drain_timeout"`
+	Retry        RetryConfig   `yaml:"retry"`
+}
+
+// RetryConfig represents the retry policy applied to failed exports
+type RetryConfig struct {
+	MaxAttempts          int           `yaml:"max_attempts" mapstructure:"max_attempts"`
+	InitialBackoff       time.Duration `yaml:"initial_backoff" mapstructure:"initial_backoff"`
+	MaxBackoff           time.Duration `yaml:"max_backoff" mapstructure:"max_backoff"`
+	Multiplier           float64       `yaml:"multiplier"`
+	Jitter               *float64      `yaml:"jitter"`
+	RetryableStatusCodes []int         `yaml:"retryable_status_codes" mapstructure:"retryable_status_codes"`
 }
 
 // RedisConfig represents Redis connection configuration
@@ -48,4 +211,20 @@ type RedisConfig struct {
 // ServerConfig represents HTTP server configuration
 type ServerConfig struct {
 	Port int `yaml:"port"`
+	// AdminToken enables the dead-letter endpoints, authenticated with it as a bearer token
+	AdminToken string          `yaml:"admin_token" mapstructure:"admin_token"`
+	Readiness  ReadinessConfig `yaml:"readiness"`
+}
+
+// ReadinessConfig sets the thresholds of the readiness endpoint
+type ReadinessConfig struct {
+	// SourceFailureThreshold is how long a source may fail before it is unhealthy
+	SourceFailureThreshold time.Duration `yaml:"source_failure_threshold" mapstructure:"source_failure_threshold"`
+	// ExporterFailureThreshold is the number of consecutive failed deliveries
+	// after which an exporter is unhealthy
+	ExporterFailureThreshold int `yaml:"exporter_failure_threshold" mapstructure:"exporter_failure_threshold"`
+	// FailOnDegraded reports the instance as not ready when any source or
+	// exporter is unhealthy, not only when the store is unreachable or every
+	// source is unhealthy
+	FailOnDegraded bool `yaml:"fail_on_degraded" mapstructure:"fail_on_degraded"`
 } 
\ No newline at end of file
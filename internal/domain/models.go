@@ -1,6 +1,10 @@
 package domain
 
-import "time"
+import (
+	"context"
+	"fmt"
+	"time"
+)
 
 // Item represents a feed item from any source
 type Item struct {
@@ -9,7 +13,9 @@ type Item struct {
 	Description string    `json:"description"`
 	Link        string    `json:"link"`
 	PublishedAt time.Time `json:"published_at"`
+	Categories  []string  `json:"categories,omitempty"`
 	Source      string    `json:"source"`
+	SourceID    string    `json:"source_id,omitempty"`
 	Group       string    `json:"group"`
 }
 
@@ -22,7 +28,8 @@ type Group struct {
 
 // Source represents a data source (e.g., RSS feed)
 type Source interface {
-	Fetch() ([]Item, error)
+	Fetch(ctx context.Context) (*FetchResult, error)
+	GetID() string
 	GetType() string
 	GetInterval() time.Duration
 	GetGroup() string
@@ -30,22 +37,123 @@ type Source interface {
 
 // Exporter represents a notification target
 type Exporter interface {
-	Export(item Item) error
+	Export(ctx context.Context, item Item) error
+	GetID() string
 	GetType() string
 	GetGroup() string
 }
 
+// BatchExporter is implemented by exporters able to send several items as a
+// single notification
+type BatchExporter interface {
+	Exporter
+	ExportBatch(ctx context.Context, items []Item) error
+}
+
 // Store represents the data persistence layer
 type Store interface {
-	HasProcessed(itemID, exporterID string) (bool, error)
-	MarkProcessed(itemID, exporterID string, sourceTTL *time.Duration) error
+	HasProcessed(ctx context.Context, itemID, exporterID string) (bool, error)
+	MarkProcessed(ctx context.Context, itemID, exporterID string, sourceTTL *time.Duration) error
+	GetFetchCache(ctx context.Context, sourceID string) (*FetchCache, error)
+	SetFetchCache(ctx context.Context, sourceID string, cache *FetchCache) error
+	GetSourceState(ctx context.Context, sourceID string) (*SourceState, error)
+	SaveSourceState(ctx context.Context, sourceID string, state *SourceState) error
+	AddDeadLetter(ctx context.Context, entry *DeadLetter) error
+	ListDeadLetters(ctx context.Context) ([]DeadLetter, error)
+	RemoveDeadLetter(ctx context.Context, id string) error
+	AppendBuffer(ctx context.Context, name string, entry BufferedItem) (int, error)
+	ReadBuffer(ctx context.Context, name string) ([]BufferedItem, error)
+	RemoveFromBuffer(ctx context.Context, name string, itemIDs []string) error
+	Ping(ctx context.Context) error
 	Close() error
 }
 
+// SkippedItem is a fetched item that a source does not return, with the
+// reason it was skipped
+type SkippedItem struct {
+	Item   Item   `json:"item"`
+	Reason string `json:"reason"`
+}
+
+// BufferedItem is an item waiting in a store buffer for its delivery, with
+// the TTL of its source bounding how long it is remembered once delivered
+type BufferedItem struct {
+	Item      Item           `json:"item"`
+	SourceTTL *time.Duration `json:"source_ttl,omitempty"`
+}
+
+// FetchResult holds the items returned by a source fetch, and the source
+// state and HTTP validators to save once they are processed
+type FetchResult struct {
+	Items []Item
+	State *SourceState
+	Cache *FetchCache
+}
+
+// SourceState tracks how far a source has been consumed so that polling can
+// resume where it left off across restarts and replicas
+type SourceState struct {
+	Cursor      time.Time `json:"cursor"`
+	SeenIDs     []string  `json:"seen_ids"`
+	LastSuccess time.Time `json:"last_success"`
+}
+
+// HasSeen reports whether an item ID was part of the last successful fetch
+func (s *SourceState) HasSeen(itemID string) bool {
+	for _, id := range s.SeenIDs {
+		if id == itemID {
+			return true
+		}
+	}
+	return false
+}
+
+// FetchCache holds the HTTP validators of the last successful source fetch
+type FetchCache struct {
+	ETag         string `json:"etag,omitempty"`
+	LastModified string `json:"last_modified,omitempty"`
+}
+
 // Notification represents a processed notification
 type Notification struct {
 	Item      Item      `json:"item"`
 	Exporter  string    `json:"exporter"`
 	Group     string    `json:"group"`
 	CreatedAt time.Time `json:"created_at"`
-} 
\ No newline at end of file
+} 
+
+// DeadLetter represents a delivery that failed after exhausting its retries
+type DeadLetter struct {
+	ID         string    `json:"id"`
+	Item       Item      `json:"item"`
+	Items      []Item    `json:"items,omitempty"`
+	ExporterID string    `json:"exporter_id"`
+	Attempts   int       `json:"attempts"`
+	StatusCode int       `json:"status_code,omitempty"`
+	LastError  string    `json:"last_error"`
+	FailedAt   time.Time `json:"failed_at"`
+	// SourceTTLs holds the TTL of the source of each item, by item ID
+	SourceTTLs map[string]time.Duration `json:"source_ttls,omitempty"`
+}
+
+// ExportError describes a failed export attempt reported by an exporter
+type ExportError struct {
+	StatusCode int
+	RetryAfter time.Duration
+	// Permanent marks failures that cannot succeed on retry
+	Permanent bool
+	Err       error
+}
+
+// Error implements the error interface
+func (e *ExportError) Error() string {
+	if e.StatusCode != 0 {
+		return fmt.Sprintf("status=%d error=%v", e.StatusCode, e.Err)
+	}
+	return e.Err.Error()
+}
+
+// Unwrap returns the underlying error
+func (e *ExportError) Unwrap() error {
+	return e.Err
+}
@@ -0,0 +1,102 @@
+package exporters
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	defaultSignatureHeader = "X-Bridgr-Signature"
+	defaultTimestampHeader = "X-Bridgr-Timestamp"
+)
+
+// requestAuth holds the static headers, credentials and signing secret added
+// to outgoing webhook requests
+type requestAuth struct {
+	headers         http.Header
+	authType        string
+	token           string
+	username        string
+	password        string
+	signingSecret   []byte
+	signatureHeader string
+	timestampHeader string
+}
+
+// newRequestAuth builds the request authentication from the exporter options
+//
+//	headers:  static headers
+//	auth:     {type: bearer, token} or {type: basic, username, password}
+//	signing:  {secret, header, timestamp_header}
+func newRequestAuth(options map[string]interface{}) (*requestAuth, error) {
+	a := &requestAuth{
+		headers: http.Header{},
+	}
+
+	for name, value := range optionMap(options, "headers") {
+		a.headers.Set(name, fmt.Sprint(value))
+	}
+
+	if auth := optionMap(options, "auth"); auth != nil {
+		a.authType = strings.ToLower(optionString(auth, "type"))
+		switch a.authType {
+		case "bearer":
+			a.token = optionString(auth, "token")
+			if a.token == "" {
+				return nil, fmt.Errorf("auth: bearer token cannot be empty")
+			}
+		case "basic":
+			a.username = optionString(auth, "username")
+			a.password = optionString(auth, "password")
+		default:
+			return nil, fmt.Errorf("auth: unsupported type: %s", a.authType)
+		}
+	}
+
+	if signing := optionMap(options, "signing"); signing != nil {
+		secret := optionString(signing, "secret")
+		if secret == "" {
+			return nil, fmt.Errorf("signing: secret cannot be empty")
+		}
+		a.signingSecret = []byte(secret)
+		a.signatureHeader = optionOrDefault(signing, "header", defaultSignatureHeader)
+		a.timestampHeader = optionOrDefault(signing, "timestamp_header", defaultTimestampHeader)
+	}
+
+	return a, nil
+}
+
+// apply adds the headers, credentials and signature to a request
+func (a *requestAuth) apply(req *http.Request, body []byte) {
+	for name, values := range a.headers {
+		req.Header[name] = values
+	}
+
+	switch a.authType {
+	case "bearer":
+		req.Header.Set("Authorization", "Bearer "+a.token)
+	case "basic":
+		req.SetBasicAuth(a.username, a.password)
+	}
+
+	if a.signingSecret != nil {
+		timestamp := strconv.FormatInt(time.Now().Unix(), 10)
+		req.Header.Set(a.timestampHeader, timestamp)
+		req.Header.Set(a.signatureHeader, "sha256="+sign(a.signingSecret, timestamp, body))
+	}
+}
+
+// sign computes the hex encoded HMAC-SHA256 of "<timestamp>.<body>"
+func sign(secret []byte, timestamp string, body []byte) string {
+	mac := hmac.New(sha256.New, secret)
+	mac.Write([]byte(timestamp))
+	mac.Write([]byte("."))
+	mac.Write(body)
+	return hex.EncodeToString(mac.Sum(nil))
+}
@@ -0,0 +1,386 @@
+package exporters
+
+import (
+	"bytes"
+	"context"
+	"crypto/rand"
+	"crypto/tls"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	htmltemplate "html/template"
+	"mime"
+	"mime/multipart"
+	"mime/quotedprintable"
+	"net"
+	"net/mail"
+	"net/smtp"
+	"net/textproto"
+	"strings"
+	"text/template"
+	"time"
+
+	"github.com/leofvo/bridgr/internal/config"
+	"github.com/leofvo/bridgr/internal/domain"
+	"github.com/leofvo/bridgr/internal/ratelimit"
+	"github.com/leofvo/bridgr/pkg/logger"
+)
+
+// SMTP connection security modes
+const (
+	emailTLSNone     = "none"
+	emailTLSStartTLS = "starttls"
+	emailTLSImplicit = "tls"
+)
+
+const (
+	defaultEmailSubject = `{{if gt (len .Items) 1}}[{{.Group}}] {{len .Items}} new items{{else}}{{.Item.Title}}{{end}}`
+
+	defaultEmailText = `{{range .Items}}{{.Title}}
+{{if .Description}}
+{{.Description}}
+{{end}}
+{{if .Link}}{{.Link}}
+{{end}}Source: {{.Source}}{{if not .PublishedAt.IsZero}} - {{.PublishedAt.Format "2006-01-02 15:04 MST"}}{{end}}
+
+{{end}}`
+
+	defaultEmailHTML = `<!DOCTYPE html>
+<html>
+<body style="font-family: sans-serif;">
+{{range .Items}}<div style="margin-bottom: 24px;">
+<h2 style="margin: 0 0 8px 0;">{{if .Link}}<a href="{{.Link}}">{{.Title}}</a>{{else}}{{.Title}}{{end}}</h2>
+{{if .Description}}<p>{{.Description}}</p>{{end}}
+<p style="color: #666; font-size: small;">Source: {{.Source}}{{if not .PublishedAt.IsZero}} - {{.PublishedAt.Format "2006-01-02 15:04 MST"}}{{end}}</p>
+</div>
+{{end}}</body>
+</html>`
+)
+
+// EmailExporter implements the Exporter interface for SMTP email
+type EmailExporter struct {
+	id           string
+	config       *config.ExporterConfig
+	group        string
+	limiter      *ratelimit.Limiter
+	address      string
+	host         string
+	security     string
+	username     string
+	password     string
+	from         *mail.Address
+	to           []string
+	cc           []string
+	insecure     bool
+	subject      *template.Template
+	textTemplate *template.Template
+	htmlTemplate *htmltemplate.Template
+}
+
+// NewEmailExporter creates a new email exporter. The exporter value is the
+// SMTP server address (host:port).
+func NewEmailExporter(cfg *config.ExporterConfig, group string) (*EmailExporter, error) {
+	host, _, err := net.SplitHostPort(cfg.Value)
+	if err != nil {
+		return nil, fmt.Errorf("invalid SMTP server address %q: %w", cfg.Value, err)
+	}
+
+	security := strings.ToLower(optionString(cfg.Options, "tls"))
+	switch security {
+	case "":
+		security = emailTLSStartTLS
+	case emailTLSNone, emailTLSStartTLS, emailTLSImplicit:
+	default:
+		return nil, fmt.Errorf("unsupported email tls mode: %s", security)
+	}
+
+	from, err := mail.ParseAddress(optionString(cfg.Options, "from"))
+	if err != nil {
+		return nil, fmt.Errorf("invalid email from address: %w", err)
+	}
+
+	to, err := parseAddresses(optionStrings(cfg.Options, "to"))
+	if err != nil {
+		return nil, fmt.Errorf("invalid email to address: %w", err)
+	}
+	if len(to) == 0 {
+		return nil, fmt.Errorf("email exporter requires at least one to address")
+	}
+
+	cc, err := parseAddresses(optionStrings(cfg.Options, "cc"))
+	if err != nil {
+		return nil, fmt.Errorf("invalid email cc address: %w", err)
+	}
+
+	subject, err := parseTemplate("subject", optionOrDefault(cfg.Options, "subject", defaultEmailSubject))
+	if err != nil {
+		return nil, fmt.Errorf("invalid email subject template: %w", err)
+	}
+
+	textTemplate, err := parseTemplate("text", optionOrDefault(cfg.Options, "text_template", defaultEmailText))
+	if err != nil {
+		return nil, fmt.Errorf("invalid email text template: %w", err)
+	}
+
+	htmlSource := optionOrDefault(cfg.Options, "html_template", defaultEmailHTML)
+	htmlTemplate, err := htmltemplate.New("html").Funcs(htmltemplate.FuncMap(templateFuncs)).Parse(htmlSource)
+	if err != nil {
+		return nil, fmt.Errorf("invalid email html template: %w", err)
+	}
+
+	return &EmailExporter{
+		id:           cfg.ResolveID(group),
+		config:       cfg,
+		group:        group,
+		limiter:      newLimiter(cfg),
+		address:      cfg.Value,
+		host:         host,
+		security:     security,
+		username:     optionString(cfg.Options, "username"),
+		password:     optionString(cfg.Options, "password"),
+		from:         from,
+		to:           to,
+		cc:           cc,
+		insecure:     optionBool(cfg.Options, "insecure_skip_verify", false),
+		subject:      subject,
+		textTemplate: textTemplate,
+		htmlTemplate: htmlTemplate,
+	}, nil
+}
+
+// Export sends an item by email
+func (e *EmailExporter) Export(ctx context.Context, item domain.Item) error {
+	return e.ExportBatch(ctx, []domain.Item{item})
+}
+
+// ExportBatch sends several items in a single email
+func (e *EmailExporter) ExportBatch(ctx context.Context, items []domain.Item) error {
+	if len(items) == 0 {
+		return nil
+	}
+
+	message, err := e.buildMessage(items)
+	if err != nil {
+		return fmt.Errorf("failed to build email: items=%d error=%w", len(items), err)
+	}
+
+	if err := waitLimiter(ctx, e.id, e.limiter); err != nil {
+		return err
+	}
+
+	if err := e.send(ctx, message); err != nil {
+		return fmt.Errorf("failed to send email: server=%s items=%d: %w", e.address, len(items), err)
+	}
+
+	logger.Info("Sent email: exporter=%s server=%s items=%d first_item=%s", e.id, e.address, len(items), items[0].ID)
+	return nil
+}
+
+// buildMessage renders the multipart email for the given items
+func (e *EmailExporter) buildMessage(items []domain.Item) ([]byte, error) {
+	data := newTemplateData(items, e.group, e.id)
+
+	var subject, text, html bytes.Buffer
+	if err := e.subject.Execute(&subject, data); err != nil {
+		return nil, fmt.Errorf("failed to render subject: %w", err)
+	}
+	if err := e.textTemplate.Execute(&text, data); err != nil {
+		return nil, fmt.Errorf("failed to render text body: %w", err)
+	}
+	if err := e.htmlTemplate.Execute(&html, data); err != nil {
+		return nil, fmt.Errorf("failed to render html body: %w", err)
+	}
+
+	var body bytes.Buffer
+	parts := multipart.NewWriter(&body)
+	if err := writeQuotedPrintablePart(parts, "text/plain; charset=UTF-8", text.Bytes()); err != nil {
+		return nil, err
+	}
+	if err := writeQuotedPrintablePart(parts, "text/html; charset=UTF-8", html.Bytes()); err != nil {
+		return nil, err
+	}
+	if err := parts.Close(); err != nil {
+		return nil, err
+	}
+
+	var message bytes.Buffer
+	headers := []struct{ key, value string }{
+		{"From", e.from.String()},
+		{"To", strings.Join(e.to, ", ")},
+		{"Subject", mime.QEncoding.Encode("utf-8", headerValue(subject.String()))},
+		{"Date", time.Now().Format(time.RFC1123Z)},
+		{"Message-ID", fmt.Sprintf("<%s@%s>", randomID(), e.from.Address[strings.LastIndex(e.from.Address, "@")+1:])},
+		{"MIME-Version", "1.0"},
+		{"Content-Type", fmt.Sprintf("multipart/alternative; boundary=%q", parts.Boundary())},
+	}
+	if len(e.cc) > 0 {
+		headers = append(headers, struct{ key, value string }{"Cc", strings.Join(e.cc, ", ")})
+	}
+	for _, header := range headers {
+		fmt.Fprintf(&message, "%s: %s\r\n", header.key, header.value)
+	}
+	message.WriteString("\r\n")
+	message.Write(body.Bytes())
+
+	return message.Bytes(), nil
+}
+
+// send delivers a message over SMTP
+func (e *EmailExporter) send(ctx context.Context, message []byte) error {
+	tlsConfig := &tls.Config{
+		ServerName:         e.host,
+		InsecureSkipVerify: e.insecure,
+	}
+
+	dialer := &net.Dialer{Timeout: 10 * time.Second}
+	var conn net.Conn
+	var err error
+	if e.security == emailTLSImplicit {
+		conn, err = (&tls.Dialer{NetDialer: dialer, Config: tlsConfig}).DialContext(ctx, "tcp", e.address)
+	} else {
+		conn, err = dialer.DialContext(ctx, "tcp", e.address)
+	}
+	if err != nil {
+		return &domain.ExportError{Err: err}
+	}
+
+	// Bound the SMTP session and abort it when the context is cancelled
+	conn.SetDeadline(time.Now().Add(time.Minute))
+	stop := context.AfterFunc(ctx, func() { conn.Close() })
+	defer stop()
+
+	client, err := smtp.NewClient(conn, e.host)
+	if err != nil {
+		conn.Close()
+		return smtpError(err)
+	}
+	defer client.Close()
+
+	if e.security == emailTLSStartTLS {
+		if ok, _ := client.Extension("STARTTLS"); !ok {
+			return &domain.ExportError{Err: errors.New("server does not support STARTTLS"), Permanent: true}
+		}
+		if err := client.StartTLS(tlsConfig); err != nil {
+			return smtpError(err)
+		}
+	}
+
+	if e.username != "" {
+		if err := client.Auth(smtp.PlainAuth("", e.username, e.password, e.host)); err != nil {
+			return smtpError(err)
+		}
+	}
+
+	if err := client.Mail(e.from.Address); err != nil {
+		return smtpError(err)
+	}
+	for _, rcpt := range append(e.to, e.cc...) {
+		address, _ := mail.ParseAddress(rcpt)
+		if err := client.Rcpt(address.Address); err != nil {
+			return smtpError(err)
+		}
+	}
+
+	w, err := client.Data()
+	if err != nil {
+		return smtpError(err)
+	}
+	if _, err := w.Write(message); err != nil {
+		return smtpError(err)
+	}
+	if err := w.Close(); err != nil {
+		return smtpError(err)
+	}
+
+	// The message is accepted once DATA completes, failing now would send it again
+	if err := client.Quit(); err != nil {
+		logger.Warn("Failed to close SMTP session after delivery: server=%s error=%v", e.address, err)
+	}
+	return nil
+}
+
+// GetID returns the exporter identifier used for deduplication
+func (e *EmailExporter) GetID() string {
+	return e.id
+}
+
+// GetType returns the exporter type
+func (e *EmailExporter) GetType() string {
+	return "email"
+}
+
+// GetGroup returns the group name
+func (e *EmailExporter) GetGroup() string {
+	return e.group
+}
+
+// GetRetryConfig returns the exporter-specific retry policy if configured
+func (e *EmailExporter) GetRetryConfig() *config.RetryConfig {
+	return e.config.Retry
+}
+
+// GetDigestConfig returns the exporter digest configuration if configured
+func (e *EmailExporter) GetDigestConfig() *config.DigestConfig {
+	return e.config.Digest
+}
+
+// smtpError marks transient SMTP failures (4xx replies and connection errors)
+// as retryable, and 5xx replies as permanent
+func smtpError(err error) error {
+	var protoErr *textproto.Error
+	if errors.As(err, &protoErr) && protoErr.Code >= 500 {
+		return &domain.ExportError{Err: err, Permanent: true}
+	}
+	return &domain.ExportError{Err: err}
+}
+
+// writeQuotedPrintablePart adds a quoted-printable encoded part
+func writeQuotedPrintablePart(parts *multipart.Writer, contentType string, content []byte) error {
+	header := textproto.MIMEHeader{}
+	header.Set("Content-Type", contentType)
+	header.Set("Content-Transfer-Encoding", "quoted-printable")
+
+	part, err := parts.CreatePart(header)
+	if err != nil {
+		return err
+	}
+
+	w := quotedprintable.NewWriter(part)
+	if _, err := w.Write(content); err != nil {
+		return err
+	}
+	return w.Close()
+}
+
+// parseAddresses validates a list of email addresses and normalizes them
+func parseAddresses(values []string) ([]string, error) {
+	addresses := make([]string, 0, len(values))
+	for _, value := range values {
+		address, err := mail.ParseAddress(value)
+		if err != nil {
+			return nil, fmt.Errorf("%q: %w", value, err)
+		}
+		addresses = append(addresses, address.String())
+	}
+	return addresses, nil
+}
+
+// headerValue flattens a rendered value into a single header line
+func headerValue(value string) string {
+	return strings.Join(strings.Fields(value), " ")
+}
+
+// optionOrDefault returns a string option, or the fallback if unset
+func optionOrDefault(options map[string]interface{}, key, fallback string) string {
+	if value := optionString(options, key); value != "" {
+		return value
+	}
+	return fallback
+}
+
+// randomID returns a random hexadecimal identifier
+func randomID() string {
+	b := make([]byte, 12)
+	rand.Read(b)
+	return hex.EncodeToString(b)
+}
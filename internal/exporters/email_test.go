@@ -0,0 +1,245 @@
+package exporters
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"io"
+	"net"
+	"net/textproto"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/leofvo/bridgr/internal/config"
+	"github.com/leofvo/bridgr/internal/domain"
+)
+
+// fakeSMTPServer is an in-process SMTP server recording the messages it receives
+type fakeSMTPServer struct {
+	listener net.Listener
+	// replies overrides the reply to a command, keyed by its verb
+	replies map[string]string
+
+	mu         sync.Mutex
+	from       string
+	recipients []string
+	data       string
+}
+
+// newFakeSMTPServer starts a fake SMTP server on a local port
+func newFakeSMTPServer(t *testing.T, replies map[string]string) *fakeSMTPServer {
+	t.Helper()
+
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("failed to listen: %v", err)
+	}
+	s := &fakeSMTPServer{listener: listener, replies: replies}
+	t.Cleanup(func() { listener.Close() })
+
+	go func() {
+		for {
+			conn, err := listener.Accept()
+			if err != nil {
+				return
+			}
+			go s.serve(conn)
+		}
+	}()
+
+	return s
+}
+
+// serve handles a single SMTP session
+func (s *fakeSMTPServer) serve(conn net.Conn) {
+	defer conn.Close()
+	text := textproto.NewConn(conn)
+
+	text.PrintfLine("220 localhost fake SMTP")
+	for {
+		line, err := text.ReadLine()
+		if err != nil {
+			return
+		}
+		verb := strings.ToUpper(strings.Fields(line + " ")[0])
+
+		if reply, ok := s.replies[verb]; ok {
+			if reply == "" {
+				// An empty reply drops the connection
+				return
+			}
+			text.PrintfLine("%s", reply)
+			continue
+		}
+
+		switch verb {
+		case "EHLO", "HELO":
+			text.PrintfLine("250 localhost")
+		case "MAIL":
+			s.mu.Lock()
+			s.from = smtpArgument(line)
+			s.mu.Unlock()
+			text.PrintfLine("250 OK")
+		case "RCPT":
+			s.mu.Lock()
+			s.recipients = append(s.recipients, smtpArgument(line))
+			s.mu.Unlock()
+			text.PrintfLine("250 OK")
+		case "DATA":
+			text.PrintfLine("354 Send message")
+			data, err := io.ReadAll(text.DotReader())
+			if err != nil {
+				return
+			}
+			s.mu.Lock()
+			s.data = string(data)
+			s.mu.Unlock()
+			text.PrintfLine("250 Queued")
+		case "QUIT":
+			text.PrintfLine("221 Bye")
+			return
+		default:
+			text.PrintfLine("502 Command not implemented")
+		}
+	}
+}
+
+// message returns the envelope and content of the last received message
+func (s *fakeSMTPServer) message() (string, []string, string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.from, s.recipients, s.data
+}
+
+// smtpArgument extracts the address of a MAIL or RCPT command
+func smtpArgument(line string) string {
+	start, end := strings.Index(line, "<"), strings.Index(line, ">")
+	if start < 0 || end < start {
+		return ""
+	}
+	return line[start+1 : end]
+}
+
+// newTestEmailExporter creates an email exporter sending to a fake server
+func newTestEmailExporter(t *testing.T, server *fakeSMTPServer) *EmailExporter {
+	t.Helper()
+
+	exporter, err := NewEmailExporter(&config.ExporterConfig{
+		Type:  config.ExporterTypeEmail,
+		Value: server.listener.Addr().String(),
+		Options: map[string]interface{}{
+			"tls":  "none",
+			"from": "Bridgr <bridgr@example.com>",
+			"to":   []interface{}{"ops@example.com"},
+			"cc":   "oncall@example.com",
+		},
+	}, "news")
+	if err != nil {
+		t.Fatalf("failed to create exporter: %v", err)
+	}
+	return exporter
+}
+
+var testEmailItem = domain.Item{
+	ID:          "item-1",
+	Title:       "Release 2.0 is out",
+	Description: "Highlights of the release",
+	Link:        "https://example.com/releases/2.0",
+	PublishedAt: time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC),
+	Source:      "https://example.com/feed.xml",
+	Group:       "news",
+}
+
+func TestEmailExporterSendsMessage(t *testing.T) {
+	server := newFakeSMTPServer(t, nil)
+	exporter := newTestEmailExporter(t, server)
+
+	if err := exporter.Export(context.Background(), testEmailItem); err != nil {
+		t.Fatalf("Export() error = %v", err)
+	}
+
+	from, recipients, data := server.message()
+	if from != "bridgr@example.com" {
+		t.Errorf("MAIL FROM = %q, want %q", from, "bridgr@example.com")
+	}
+	if got := strings.Join(recipients, ","); got != "ops@example.com,oncall@example.com" {
+		t.Errorf("RCPT TO = %q, want ops@example.com,oncall@example.com", got)
+	}
+
+	header, err := textproto.NewReader(bufio.NewReader(strings.NewReader(data))).ReadMIMEHeader()
+	if err != nil {
+		t.Fatalf("failed to parse message headers: %v", err)
+	}
+	if !strings.Contains(header.Get("Subject"), "Release 2.0 is out") {
+		t.Errorf("Subject = %q, want the item title", header.Get("Subject"))
+	}
+	if !strings.HasPrefix(header.Get("Content-Type"), "multipart/alternative") {
+		t.Errorf("Content-Type = %q, want multipart/alternative", header.Get("Content-Type"))
+	}
+	for _, want := range []string{"text/plain", "text/html", testEmailItem.Link} {
+		if !strings.Contains(data, want) {
+			t.Errorf("message does not contain %q", want)
+		}
+	}
+}
+
+func TestEmailExporterIgnoresQuitFailure(t *testing.T) {
+	server := newFakeSMTPServer(t, map[string]string{"QUIT": ""})
+	exporter := newTestEmailExporter(t, server)
+
+	if err := exporter.Export(context.Background(), testEmailItem); err != nil {
+		t.Fatalf("Export() error = %v, want nil once the message is accepted", err)
+	}
+	if _, _, data := server.message(); data == "" {
+		t.Fatal("message was not delivered")
+	}
+}
+
+func TestEmailExporterReplyErrors(t *testing.T) {
+	tests := []struct {
+		name      string
+		reply     string
+		permanent bool
+	}{
+		{name: "transient", reply: "451 Try again later", permanent: false},
+		{name: "permanent", reply: "550 Mailbox unavailable", permanent: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server := newFakeSMTPServer(t, map[string]string{"RCPT": tt.reply})
+			exporter := newTestEmailExporter(t, server)
+
+			err := exporter.Export(context.Background(), testEmailItem)
+			var exportErr *domain.ExportError
+			if !errors.As(err, &exportErr) {
+				t.Fatalf("Export() error = %v, want a domain.ExportError", err)
+			}
+			if exportErr.Permanent != tt.permanent {
+				t.Errorf("Permanent = %v, want %v", exportErr.Permanent, tt.permanent)
+			}
+		})
+	}
+}
+
+func TestEmailExporterBatch(t *testing.T) {
+	server := newFakeSMTPServer(t, nil)
+	exporter := newTestEmailExporter(t, server)
+
+	second := testEmailItem
+	second.ID = "item-2"
+	second.Title = "Security advisory"
+	if err := exporter.ExportBatch(context.Background(), []domain.Item{testEmailItem, second}); err != nil {
+		t.Fatalf("ExportBatch() error = %v", err)
+	}
+
+	_, _, data := server.message()
+	if want := "[news] 2 new items"; !strings.Contains(data, want) {
+		t.Errorf("subject does not contain %q", want)
+	}
+	if !strings.Contains(data, "Security advisory") {
+		t.Error("message does not contain the second item")
+	}
+}
@@ -18,8 +18,14 @@ func NewFactory() *Factory {
 // CreateExporter creates a new exporter based on the configuration
 func (f *Factory) CreateExporter(cfg *config.ExporterConfig, group string) (domain.Exporter, error) {
 	switch cfg.Type {
-	case "webhook":
-		return NewWebhookExporter(cfg, group), nil
+	case config.ExporterTypeWebhook:
+		return NewWebhookExporter(cfg, group)
+	case config.ExporterTypeSlack:
+		return NewSlackExporter(cfg, group)
+	case config.ExporterTypeTelegram:
+		return NewTelegramExporter(cfg, group)
+	case config.ExporterTypeEmail:
+		return NewEmailExporter(cfg, group)
 	default:
 		return nil, fmt.Errorf("unknown exporter type: %s", cfg.Type)
 	}
@@ -0,0 +1,70 @@
+package exporters
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// optionString returns a string option, or an empty string if unset
+func optionString(options map[string]interface{}, key string) string {
+	value, ok := options[key]
+	if !ok || value == nil {
+		return ""
+	}
+	if s, ok := value.(string); ok {
+		return s
+	}
+	return fmt.Sprint(value)
+}
+
+// optionBool returns a boolean option, or the fallback if unset or invalid
+func optionBool(options map[string]interface{}, key string, fallback bool) bool {
+	switch value := options[key].(type) {
+	case bool:
+		return value
+	case string:
+		if parsed, err := strconv.ParseBool(value); err == nil {
+			return parsed
+		}
+	}
+	return fallback
+}
+
+// optionStrings returns a list option given either as a YAML list or as a
+// comma-separated string
+func optionStrings(options map[string]interface{}, key string) []string {
+	var values []string
+	switch value := options[key].(type) {
+	case []interface{}:
+		for _, v := range value {
+			values = append(values, strings.TrimSpace(fmt.Sprint(v)))
+		}
+	case []string:
+		for _, v := range value {
+			values = append(values, strings.TrimSpace(v))
+		}
+	case string:
+		for _, v := range strings.Split(value, ",") {
+			if v = strings.TrimSpace(v); v != "" {
+				values = append(values, v)
+			}
+		}
+	}
+	return values
+}
+
+// optionMap returns a nested options map, or nil if unset
+func optionMap(options map[string]interface{}, key string) map[string]interface{} {
+	switch value := options[key].(type) {
+	case map[string]interface{}:
+		return value
+	case map[interface{}]interface{}:
+		converted := make(map[string]interface{}, len(value))
+		for k, v := range value {
+			converted[fmt.Sprint(k)] = v
+		}
+		return converted
+	}
+	return nil
+}
@@ -0,0 +1,150 @@
+package exporters
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/leofvo/bridgr/internal/config"
+	"github.com/leofvo/bridgr/internal/domain"
+	"github.com/leofvo/bridgr/internal/metrics"
+	"github.com/leofvo/bridgr/internal/ratelimit"
+	"github.com/leofvo/bridgr/pkg/logger"
+)
+
+const (
+	// defaultMaxRateLimitRetries caps the retries of a rate limited request
+	defaultMaxRateLimitRetries = 3
+
+	// defaultRetryAfter is used when a rate limited response has no delay hint
+	defaultRetryAfter = time.Second
+)
+
+// sendFunc performs a single request and returns the response with its body
+type sendFunc func(ctx context.Context) (*http.Response, []byte, error)
+
+// sendWithRateLimit waits for the limiter before each attempt and retries up
+// to maxRetries times when the service answers 429 Too Many Requests. The
+// requested delay pauses the limiter so concurrent exports back off as well.
+// Transport failures and exhausted retries are returned as domain.ExportError.
+func sendWithRateLimit(ctx context.Context, exporterID string, limiter *ratelimit.Limiter, maxRetries int, send sendFunc) (*http.Response, []byte, error) {
+	for retries := 0; ; retries++ {
+		if err := waitLimiter(ctx, exporterID, limiter); err != nil {
+			return nil, nil, err
+		}
+
+		resp, body, err := send(ctx)
+		if err != nil {
+			return nil, nil, &domain.ExportError{Err: err}
+		}
+
+		if resp.StatusCode != http.StatusTooManyRequests {
+			return resp, body, nil
+		}
+
+		metrics.RateLimitHits.WithLabelValues(exporterID).Inc()
+		retryAfter := parseRetryAfter(resp.Header, body)
+		limiter.Pause(retryAfter)
+
+		if retries >= maxRetries {
+			return nil, nil, &domain.ExportError{
+				StatusCode: resp.StatusCode,
+				RetryAfter: retryAfter,
+				Err:        fmt.Errorf("rate limited after %d retries", retries),
+			}
+		}
+
+		logger.Debug("Rate limit hit: waiting for %v before retry %d", retryAfter, retries+1)
+	}
+}
+
+// waitLimiter waits for the rate limiter of an exporter, recording the time spent
+func waitLimiter(ctx context.Context, exporterID string, limiter *ratelimit.Limiter) error {
+	start := time.Now()
+	defer func() {
+		metrics.RateLimitWaitDuration.WithLabelValues(exporterID).Observe(time.Since(start).Seconds())
+	}()
+
+	if err := limiter.Wait(ctx); err != nil {
+		return fmt.Errorf("failed to wait for rate limit: %w", err)
+	}
+	return nil
+}
+
+// doRequest sends a request and reads the whole response body
+func doRequest(client *http.Client, req *http.Request) (*http.Response, []byte, error) {
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, nil, err
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to read response: %w", err)
+	}
+
+	return resp, body, nil
+}
+
+// maxRateLimitRetries returns the configured cap of rate limit retries, 0
+// disabling them
+func maxRateLimitRetries(cfg *config.ExporterConfig) int {
+	if cfg.RateLimit != nil && cfg.RateLimit.MaxRetries != nil {
+		return *cfg.RateLimit.MaxRetries
+	}
+	return defaultMaxRateLimitRetries
+}
+
+// newLimiter creates the limiter of an exporter. Without a configured rate it
+// only enforces the pauses requested by the remote service.
+func newLimiter(cfg *config.ExporterConfig) *ratelimit.Limiter {
+	var requestsPerSecond float64
+	if cfg.RateLimit != nil {
+		requestsPerSecond = cfg.RateLimit.RequestsPerSecond
+	}
+	return ratelimit.NewLimiter(requestsPerSecond)
+}
+
+// rateLimitBody covers the JSON bodies returned with a 429 by the supported
+// services: Discord ("retry_after" in seconds) and Telegram
+// ("parameters.retry_after" in seconds)
+type rateLimitBody struct {
+	RetryAfter float64 `json:"retry_after"`
+	Parameters struct {
+		RetryAfter float64 `json:"retry_after"`
+	} `json:"parameters"`
+}
+
+// parseRetryAfter extracts the delay requested by a rate limited response,
+// from the Retry-After header (delay in seconds or HTTP date, used by Slack
+// and Teams) or from the JSON body (Discord, Telegram)
+func parseRetryAfter(header http.Header, body []byte) time.Duration {
+	if value := header.Get("Retry-After"); value != "" {
+		if seconds, err := strconv.ParseFloat(value, 64); err == nil && seconds >= 0 {
+			return time.Duration(seconds * float64(time.Second))
+		}
+		if date, err := http.ParseTime(value); err == nil {
+			if wait := time.Until(date); wait > 0 {
+				return wait
+			}
+			return 0
+		}
+	}
+
+	var parsed rateLimitBody
+	if err := json.Unmarshal(body, &parsed); err == nil {
+		if parsed.RetryAfter > 0 {
+			return time.Duration(parsed.RetryAfter * float64(time.Second))
+		}
+		if parsed.Parameters.RetryAfter > 0 {
+			return time.Duration(parsed.Parameters.RetryAfter * float64(time.Second))
+		}
+	}
+
+	return defaultRetryAfter
+}
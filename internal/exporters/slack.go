@@ -0,0 +1,184 @@
+package exporters
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/leofvo/bridgr/internal/config"
+	"github.com/leofvo/bridgr/internal/domain"
+	"github.com/leofvo/bridgr/internal/utils"
+)
+
+// Slack Block Kit text limits
+const (
+	slackMaxFallbackText = 4000
+	slackMaxHeaderText   = 150
+	slackMaxSectionText  = 3000
+	slackMaxContextText  = 3000
+	slackMaxURL          = 3000
+	slackMaxBlocks       = 50
+)
+
+// SlackWebhook represents a Slack incoming webhook payload
+type SlackWebhook struct {
+	Text   string       `json:"text"`
+	Blocks []SlackBlock `json:"blocks"`
+}
+
+// SlackBlock represents a Slack Block Kit layout block
+type SlackBlock struct {
+	Type     string         `json:"type"`
+	Text     *SlackText     `json:"text,omitempty"`
+	Elements []SlackElement `json:"elements,omitempty"`
+}
+
+// SlackText represents a Slack text object
+type SlackText struct {
+	Type  string `json:"type"`
+	Text  string `json:"text"`
+	Emoji bool   `json:"emoji,omitempty"`
+}
+
+// SlackElement represents a Slack block element (text or button)
+type SlackElement struct {
+	Type string      `json:"type"`
+	Text interface{} `json:"text"`
+	URL  string      `json:"url,omitempty"`
+}
+
+// NewSlackExporter creates a webhook exporter sending Slack Block Kit payloads
+func NewSlackExporter(cfg *config.ExporterConfig, group string) (*WebhookExporter, error) {
+	return newWebhookExporter(cfg, group, "slack", "slack")
+}
+
+// createSlackPayload creates a Slack Block Kit webhook payload
+func (e *WebhookExporter) createSlackPayload(item domain.Item) SlackWebhook {
+	blocks := []SlackBlock{
+		{
+			Type: "header",
+			Text: &SlackText{
+				Type:  "plain_text",
+				Text:  utils.Truncate(item.Title, slackMaxHeaderText),
+				Emoji: true,
+			},
+		},
+	}
+
+	if item.Description != "" {
+		blocks = append(blocks, SlackBlock{
+			Type: "section",
+			Text: &SlackText{
+				Type: "mrkdwn",
+				Text: utils.Truncate(escapeSlack(item.Description), slackMaxSectionText),
+			},
+		})
+	}
+
+	contextText := fmt.Sprintf("Source: %s", escapeSlack(sourceName(item)))
+	if !item.PublishedAt.IsZero() {
+		// Slack renders the date in the reader's timezone, with a fallback for old clients
+		contextText += fmt.Sprintf(" | <!date^%d^{date_short_pretty} {time}|%s>",
+			item.PublishedAt.Unix(), item.PublishedAt.UTC().Format(time.RFC1123))
+	}
+	blocks = append(blocks, SlackBlock{
+		Type: "context",
+		Elements: []SlackElement{
+			{
+				Type: "mrkdwn",
+				Text: utils.Truncate(contextText, slackMaxContextText),
+			},
+		},
+	})
+
+	if item.Link != "" && len(item.Link) <= slackMaxURL {
+		blocks = append(blocks, SlackBlock{
+			Type: "actions",
+			Elements: []SlackElement{
+				{
+					Type: "button",
+					Text: SlackText{
+						Type: "plain_text",
+						Text: "Read more",
+					},
+					URL: item.Link,
+				},
+			},
+		})
+	}
+
+	return SlackWebhook{
+		// Fallback used in notifications and by clients without Block Kit support
+		Text:   utils.Truncate(item.Title, slackMaxFallbackText),
+		Blocks: blocks,
+	}
+}
+
+// createSlackDigest creates a Slack Block Kit payload listing several items,
+// split into sections within the Slack text and block limits
+func (e *WebhookExporter) createSlackDigest(items []domain.Item) SlackWebhook {
+	title := fmt.Sprintf("%d new items", len(items))
+	blocks := []SlackBlock{
+		{
+			Type: "header",
+			Text: &SlackText{
+				Type:  "plain_text",
+				Text:  title,
+				Emoji: true,
+			},
+		},
+	}
+
+	var section strings.Builder
+	flush := func() {
+		if section.Len() == 0 {
+			return
+		}
+		blocks = append(blocks, SlackBlock{
+			Type: "section",
+			Text: &SlackText{
+				Type: "mrkdwn",
+				Text: section.String(),
+			},
+		})
+		section.Reset()
+	}
+
+	for i, item := range items {
+		line := fmt.Sprintf("• %s (%s)\n", escapeSlack(item.Title), escapeSlack(sourceName(item)))
+		if item.Link != "" && len(item.Link) <= slackMaxURL {
+			line = fmt.Sprintf("• <%s|%s> (%s)\n", item.Link, escapeSlack(item.Title), escapeSlack(sourceName(item)))
+		}
+		line = utils.Truncate(line, slackMaxSectionText)
+
+		if section.Len()+len(line) > slackMaxSectionText {
+			// Keep a block for the summary of the remaining items
+			if len(blocks) >= slackMaxBlocks-2 {
+				flush()
+				blocks = append(blocks, SlackBlock{
+					Type: "context",
+					Elements: []SlackElement{
+						{
+							Type: "mrkdwn",
+							Text: fmt.Sprintf("And %d more", len(items)-i),
+						},
+					},
+				})
+				return SlackWebhook{Text: title, Blocks: blocks}
+			}
+			flush()
+		}
+		section.WriteString(line)
+	}
+	flush()
+
+	return SlackWebhook{
+		Text:   title,
+		Blocks: blocks,
+	}
+}
+
+// escapeSlack escapes the control characters of Slack mrkdwn
+func escapeSlack(text string) string {
+	return strings.NewReplacer("&", "&amp;", "<", "&lt;", ">", "&gt;").Replace(text)
+}
@@ -0,0 +1,317 @@
+package exporters
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"html"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/leofvo/bridgr/internal/config"
+	"github.com/leofvo/bridgr/internal/domain"
+	"github.com/leofvo/bridgr/internal/ratelimit"
+	"github.com/leofvo/bridgr/internal/utils"
+	"github.com/leofvo/bridgr/pkg/logger"
+)
+
+const (
+	// defaultTelegramAPIURL is the base URL of the Telegram Bot API
+	defaultTelegramAPIURL = "https://api.telegram.org"
+
+	// telegramMaxMessageLength is the maximum length of a message text
+	telegramMaxMessageLength = 4096
+
+	// telegramMaxDescriptionLength keeps room for the title and links
+	telegramMaxDescriptionLength = 3000
+
+	// redactedToken replaces the bot token in errors
+	redactedToken = "<token>"
+)
+
+// TelegramMessage represents a Telegram sendMessage request
+type TelegramMessage struct {
+	ChatID                string `json:"chat_id"`
+	Text                  string `json:"text"`
+	ParseMode             string `json:"parse_mode"`
+	DisableWebPagePreview bool   `json:"disable_web_page_preview"`
+	DisableNotification   bool   `json:"disable_notification"`
+}
+
+// TelegramResponse represents a Telegram Bot API response
+type TelegramResponse struct {
+	OK          bool   `json:"ok"`
+	ErrorCode   int    `json:"error_code,omitempty"`
+	Description string `json:"description,omitempty"`
+}
+
+// TelegramExporter implements the Exporter interface for the Telegram Bot API
+type TelegramExporter struct {
+	id          string
+	config      *config.ExporterConfig
+	client      *http.Client
+	group       string
+	limiter     *ratelimit.Limiter
+	apiURL      string
+	botToken    string
+	chatID      string
+	parseMode   string
+	linkPreview bool
+	silent      bool
+}
+
+// NewTelegramExporter creates a new Telegram exporter
+func NewTelegramExporter(cfg *config.ExporterConfig, group string) (*TelegramExporter, error) {
+	botToken := optionString(cfg.Options, "bot_token")
+	if botToken == "" {
+		return nil, fmt.Errorf("telegram exporter requires the bot_token option")
+	}
+
+	chatID := optionString(cfg.Options, "chat_id")
+	if chatID == "" {
+		chatID = cfg.Value
+	}
+	if chatID == "" {
+		return nil, fmt.Errorf("telegram exporter requires the chat_id option")
+	}
+
+	parseMode := optionString(cfg.Options, "parse_mode")
+	switch strings.ToLower(parseMode) {
+	case "", "html":
+		parseMode = "HTML"
+	case "markdownv2":
+		parseMode = "MarkdownV2"
+	default:
+		return nil, fmt.Errorf("unsupported telegram parse_mode: %s", parseMode)
+	}
+
+	apiURL := optionString(cfg.Options, "api_url")
+	if apiURL == "" {
+		apiURL = defaultTelegramAPIURL
+	}
+
+	return &TelegramExporter{
+		id:     cfg.ResolveID(group),
+		config: cfg,
+		client: &http.Client{
+			Timeout: 10 * time.Second,
+		},
+		group:       group,
+		limiter:     newLimiter(cfg),
+		apiURL:      strings.TrimSuffix(apiURL, "/"),
+		botToken:    botToken,
+		chatID:      chatID,
+		parseMode:   parseMode,
+		linkPreview: optionBool(cfg.Options, "link_preview", true),
+		silent:      optionBool(cfg.Options, "silent", false),
+	}, nil
+}
+
+// Export sends an item to the Telegram chat
+func (e *TelegramExporter) Export(ctx context.Context, item domain.Item) error {
+	return e.sendMessage(ctx, e.formatMessage(item), item.ID, 1)
+}
+
+// ExportBatch sends several items to the Telegram chat as a single digest message
+func (e *TelegramExporter) ExportBatch(ctx context.Context, items []domain.Item) error {
+	if len(items) == 0 {
+		return nil
+	}
+	return e.sendMessage(ctx, e.formatDigest(items), items[0].ID, len(items))
+}
+
+// sendMessage sends a formatted message to the Telegram chat
+func (e *TelegramExporter) sendMessage(ctx context.Context, text, itemID string, count int) error {
+	message := TelegramMessage{
+		ChatID:                e.chatID,
+		Text:                  text,
+		ParseMode:             e.parseMode,
+		DisableWebPagePreview: !e.linkPreview,
+		DisableNotification:   e.silent,
+	}
+
+	data, err := json.Marshal(message)
+	if err != nil {
+		return fmt.Errorf("failed to marshal telegram message: item=%s error=%w", itemID, err)
+	}
+
+	resp, body, err := sendWithRateLimit(ctx, e.id, e.limiter, maxRateLimitRetries(e.config), func(ctx context.Context) (*http.Response, []byte, error) {
+		return e.send(ctx, data)
+	})
+	if err != nil {
+		return fmt.Errorf("failed to send telegram message: item=%s chat=%s: %w", itemID, e.chatID, err)
+	}
+
+	var result TelegramResponse
+	if err := json.Unmarshal(body, &result); err != nil {
+		return &domain.ExportError{
+			StatusCode: resp.StatusCode,
+			Err:        fmt.Errorf("failed to decode telegram response: item=%s chat=%s status=%d error=%w", itemID, e.chatID, resp.StatusCode, err),
+		}
+	}
+	if !result.OK {
+		return &domain.ExportError{
+			StatusCode: resp.StatusCode,
+			Err:        fmt.Errorf("telegram request failed: item=%s chat=%s status=%d description=%s", itemID, e.chatID, resp.StatusCode, result.Description),
+		}
+	}
+
+	logger.Info("Sent telegram message: exporter=%s chat=%s item=%s items=%d", e.id, e.chatID, itemID, count)
+	return nil
+}
+
+// send posts a sendMessage request to the Bot API
+func (e *TelegramExporter) send(ctx context.Context, data []byte) (*http.Response, []byte, error) {
+	endpoint := fmt.Sprintf("%s/bot%s/sendMessage", e.apiURL, e.botToken)
+	req, err := http.NewRequestWithContext(ctx, "POST", endpoint, bytes.NewReader(data))
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to create request: %w", e.redactToken(err))
+	}
+
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, body, err := doRequest(e.client, req)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to reach the Telegram Bot API: %w", e.redactToken(err))
+	}
+	return resp, body, nil
+}
+
+// redactToken removes the bot token embedded in the request URL from an
+// error, keeping its cause
+func (e *TelegramExporter) redactToken(err error) error {
+	if urlErr, ok := err.(*url.Error); ok {
+		return &url.Error{
+			Op:  urlErr.Op,
+			URL: strings.ReplaceAll(urlErr.URL, e.botToken, redactedToken),
+			Err: e.redactToken(urlErr.Err),
+		}
+	}
+	if strings.Contains(err.Error(), e.botToken) {
+		return errors.New(strings.ReplaceAll(err.Error(), e.botToken, redactedToken))
+	}
+	return err
+}
+
+// formatMessage renders an item in the configured parse mode, shortening the
+// description until the escaped message fits in a Telegram message
+func (e *TelegramExporter) formatMessage(item domain.Item) string {
+	for limit := telegramMaxDescriptionLength; ; limit /= 2 {
+		message := e.renderMessage(item, utils.Truncate(item.Description, limit))
+		if utf8.RuneCountInString(message) <= telegramMaxMessageLength || limit == 0 {
+			return message
+		}
+	}
+}
+
+// renderMessage renders an item with the given description
+func (e *TelegramExporter) renderMessage(item domain.Item, description string) string {
+	var b strings.Builder
+	if e.parseMode == "MarkdownV2" {
+		fmt.Fprintf(&b, "*%s*", escapeMarkdownV2(item.Title))
+		if description != "" {
+			fmt.Fprintf(&b, "\n\n%s", escapeMarkdownV2(description))
+		}
+		if item.Link != "" {
+			fmt.Fprintf(&b, "\n\n[Read more](%s)", escapeMarkdownV2URL(item.Link))
+		}
+		fmt.Fprintf(&b, "\n_%s_", escapeMarkdownV2("Source: "+sourceName(item)))
+	} else {
+		fmt.Fprintf(&b, "<b>%s</b>", html.EscapeString(item.Title))
+		if description != "" {
+			fmt.Fprintf(&b, "\n\n%s", html.EscapeString(description))
+		}
+		if item.Link != "" {
+			fmt.Fprintf(&b, "\n\n<a href=\"%s\">Read more</a>", html.EscapeString(item.Link))
+		}
+		fmt.Fprintf(&b, "\n<i>Source: %s</i>", html.EscapeString(sourceName(item)))
+	}
+
+	return b.String()
+}
+
+// formatDigest renders several items as a list of links, summarizing the
+// items that do not fit in a Telegram message
+func (e *TelegramExporter) formatDigest(items []domain.Item) string {
+	var b strings.Builder
+	if e.parseMode == "MarkdownV2" {
+		fmt.Fprintf(&b, "*%s*\n", escapeMarkdownV2(fmt.Sprintf("%d new items", len(items))))
+	} else {
+		fmt.Fprintf(&b, "<b>%d new items</b>\n", len(items))
+	}
+
+	for i, item := range items {
+		var line string
+		switch {
+		case e.parseMode == "MarkdownV2" && item.Link != "":
+			line = fmt.Sprintf("\n• [%s](%s)", escapeMarkdownV2(item.Title), escapeMarkdownV2URL(item.Link))
+		case e.parseMode == "MarkdownV2":
+			line = fmt.Sprintf("\n• %s", escapeMarkdownV2(item.Title))
+		case item.Link != "":
+			line = fmt.Sprintf("\n• <a href=\"%s\">%s</a>", html.EscapeString(item.Link), html.EscapeString(item.Title))
+		default:
+			line = fmt.Sprintf("\n• %s", html.EscapeString(item.Title))
+		}
+
+		// Keep room for the summary of the remaining items
+		if utf8.RuneCountInString(b.String())+utf8.RuneCountInString(line) > telegramMaxMessageLength-64 {
+			more := fmt.Sprintf("and %d more", len(items)-i)
+			if e.parseMode == "MarkdownV2" {
+				more = escapeMarkdownV2(more)
+			}
+			fmt.Fprintf(&b, "\n… %s", more)
+			break
+		}
+		b.WriteString(line)
+	}
+
+	return b.String()
+}
+
+// GetID returns the exporter identifier used for deduplication
+func (e *TelegramExporter) GetID() string {
+	return e.id
+}
+
+// GetType returns the exporter type
+func (e *TelegramExporter) GetType() string {
+	return "telegram"
+}
+
+// GetGroup returns the group name
+func (e *TelegramExporter) GetGroup() string {
+	return e.group
+}
+
+// GetRetryConfig returns the exporter-specific retry policy if configured
+func (e *TelegramExporter) GetRetryConfig() *config.RetryConfig {
+	return e.config.Retry
+}
+
+// GetDigestConfig returns the exporter digest configuration if configured
+func (e *TelegramExporter) GetDigestConfig() *config.DigestConfig {
+	return e.config.Digest
+}
+
+// markdownV2Replacer escapes the characters reserved by Telegram MarkdownV2
+var markdownV2Replacer = strings.NewReplacer(
+	"\\", "\\\\", "_", "\\_", "*", "\\*", "[", "\\[", "]", "\\]", "(", "\\(",
+	")", "\\)", "~", "\\~", "`", "\\`", ">", "\\>", "#", "\\#", "+", "\\+",
+	"-", "\\-", "=", "\\=", "|", "\\|", "{", "\\{", "}", "\\}", ".", "\\.",
+	"!", "\\!",
+)
+
+// escapeMarkdownV2 escapes text for Telegram MarkdownV2
+func escapeMarkdownV2(text string) string {
+	return markdownV2Replacer.Replace(text)
+}
+
+// escapeMarkdownV2URL escapes the URL part of a MarkdownV2 inline link
+func escapeMarkdownV2URL(link string) string {
+	return strings.NewReplacer("\\", "\\\\", ")", "\\)").Replace(link)
+}
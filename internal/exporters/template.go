@@ -0,0 +1,90 @@
+package exporters
+
+import (
+	"encoding/json"
+	"net/url"
+	"strings"
+	"text/template"
+	"time"
+
+	"github.com/leofvo/bridgr/internal/domain"
+	"github.com/leofvo/bridgr/internal/utils"
+)
+
+// TemplateData is the data available to payload templates
+type TemplateData struct {
+	Item       domain.Item
+	Items      []domain.Item
+	Group      string
+	Source     string
+	SourceName string
+	Exporter   string
+}
+
+// newTemplateData builds the template data of one or more items
+func newTemplateData(items []domain.Item, group, exporterID string) TemplateData {
+	return TemplateData{
+		Item:       items[0],
+		Items:      items,
+		Group:      group,
+		Source:     items[0].Source,
+		SourceName: sourceName(items[0]),
+		Exporter:   exporterID,
+	}
+}
+
+// templateFuncs are the helper functions available to payload templates
+var templateFuncs = template.FuncMap{
+	"truncate": func(max int, text string) string {
+		return utils.Truncate(text, max)
+	},
+	"json": func(v interface{}) (string, error) {
+		data, err := json.Marshal(v)
+		return string(data), err
+	},
+	"jsonEscape": func(text string) (string, error) {
+		data, err := json.Marshal(text)
+		if err != nil {
+			return "", err
+		}
+		return string(data[1 : len(data)-1]), nil
+	},
+	"markdown": escapeMarkdown,
+	"date": func(layout string, t time.Time) string {
+		return t.Format(layout)
+	},
+	"hostname": func(rawURL string) string {
+		parsedURL, err := url.Parse(rawURL)
+		if err != nil {
+			return ""
+		}
+		return strings.TrimPrefix(parsedURL.Hostname(), "www.")
+	},
+	"default": func(fallback, value string) string {
+		if value == "" {
+			return fallback
+		}
+		return value
+	},
+	"upper": strings.ToUpper,
+	"lower": strings.ToLower,
+}
+
+// parseTemplate parses a template option with the payload helper functions.
+// Templates given as "${file:<path>}" are read by the configuration loader.
+func parseTemplate(name, option string) (*template.Template, error) {
+	return template.New(name).Funcs(templateFuncs).Parse(option)
+}
+
+// markdownReplacer escapes the characters interpreted by common Markdown
+// dialects (Discord, Teams, Slack mrkdwn)
+var markdownReplacer = strings.NewReplacer(
+	"\\", "\\\\", "*", "\\*", "_", "\\_", "~", "\\~", "`", "\\`",
+	"[", "\\[", "]", "\\]", "(", "\\(", ")", "\\)", "#", "\\#",
+	">", "\\>", "|", "\\|",
+)
+
+// escapeMarkdown escapes Markdown formatting characters
+func escapeMarkdown(text string) string {
+	return markdownReplacer.Replace(text)
+}
@@ -2,26 +2,45 @@ package exporters
 
 import (
 	"bytes"
+	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
-	"io"
 	"net/http"
 	"net/url"
 	"strings"
+	"text/template"
 	"time"
 
 	"github.com/leofvo/bridgr/internal/config"
 	"github.com/leofvo/bridgr/internal/domain"
 	"github.com/leofvo/bridgr/internal/ratelimit"
+	"github.com/leofvo/bridgr/internal/utils"
 	"github.com/leofvo/bridgr/pkg/logger"
 )
 
+// Discord and Teams digest limits
+const (
+	discordMaxEmbeds         = 10
+	discordMaxTitle          = 256
+	discordDigestDescription = 200
+	discordDigestOverflow    = 1400
+	teamsMaxDigestItems      = 50
+)
+
 // WebhookExporter implements the Exporter interface for webhooks
 type WebhookExporter struct {
-	config  *config.ExporterConfig
-	client  *http.Client
-	group   string
-	limiter *ratelimit.Limiter
+	id           string
+	exporterType string
+	format       string
+	template     *template.Template
+	method       string
+	contentType  string
+	auth         *requestAuth
+	config       *config.ExporterConfig
+	client       *http.Client
+	group        string
+	limiter      *ratelimit.Limiter
 }
 
 // DiscordWebhook represents a Discord webhook payload
@@ -73,97 +92,138 @@ type TeamsBlock struct {
 	Size string `json:"size,omitempty"`
 	Weight string `json:"weight,omitempty"`
 	Color string `json:"color,omitempty"`
+	Wrap bool `json:"wrap,omitempty"`
 }
 
 // NewWebhookExporter creates a new webhook exporter
-func NewWebhookExporter(cfg *config.ExporterConfig, group string) *WebhookExporter {
-	// Create rate limiter if configured
-	var limiter *ratelimit.Limiter
-	if cfg.RateLimit != nil {
-		limiter = ratelimit.NewLimiter(cfg.RateLimit.RequestsPerSecond)
+func NewWebhookExporter(cfg *config.ExporterConfig, group string) (*WebhookExporter, error) {
+	format, _ := cfg.Options["format"].(string)
+	return newWebhookExporter(cfg, group, "webhook", format)
+}
+
+// newWebhookExporter creates a webhook exporter of the given type sending
+// payloads in the given format, unless a payload template is configured
+func newWebhookExporter(cfg *config.ExporterConfig, group, exporterType, format string) (*WebhookExporter, error) {
+	var payloadTemplate *template.Template
+	if option := optionString(cfg.Options, "template"); option != "" {
+		var err error
+		payloadTemplate, err = parseTemplate("payload", option)
+		if err != nil {
+			return nil, fmt.Errorf("invalid webhook template: %w", err)
+		}
+	}
+
+	method := strings.ToUpper(optionString(cfg.Options, "method"))
+	switch method {
+	case "":
+		method = http.MethodPost
+	case http.MethodPost, http.MethodPut, http.MethodPatch:
+	default:
+		return nil, fmt.Errorf("unsupported webhook method: %s", method)
+	}
+
+	contentType := optionString(cfg.Options, "content_type")
+	if contentType == "" {
+		contentType = "application/json"
+	}
+
+	auth, err := newRequestAuth(cfg.Options)
+	if err != nil {
+		return nil, fmt.Errorf("invalid webhook authentication: %w", err)
 	}
 
 	return &WebhookExporter{
-		config:  cfg,
+		id:           cfg.ResolveID(group),
+		exporterType: exporterType,
+		format:       format,
+		template:     payloadTemplate,
+		method:       method,
+		contentType:  contentType,
+		auth:         auth,
+		config:       cfg,
 		client: &http.Client{
 			Timeout: 10 * time.Second,
 		},
 		group:   group,
-		limiter: limiter,
-	}
+		limiter: newLimiter(cfg),
+	}, nil
 }
 
-// Export sends an item to the webhook
-func (e *WebhookExporter) Export(item domain.Item) error {
-	// Wait for rate limit if configured
-	if e.limiter != nil {
-		e.limiter.Wait()
+// Export sends an item to the webhook, retrying a bounded number of times
+// when the webhook answers 429 Too Many Requests
+func (e *WebhookExporter) Export(ctx context.Context, item domain.Item) error {
+	data, err := e.buildBody([]domain.Item{item})
+	if err != nil {
+		return fmt.Errorf("failed to build payload: item=%s error=%w", item.ID, err)
 	}
 
-	var payload interface{}
+	return e.post(ctx, data, item.ID, 1)
+}
 
-	// Check the webhook format
-	if format, ok := e.config.Options["format"].(string); ok {
-		switch format {
-		case "discord":
-			payload = e.createDiscordPayload(item)
-		case "teams":
-			payload = e.createTeamsPayload(item)
-		default:
-			// Default to simple JSON payload
-			payload = item
-		}
-	} else {
-		// Default to simple JSON payload
-		payload = item
+// ExportBatch sends several items to the webhook as a single digest payload
+func (e *WebhookExporter) ExportBatch(ctx context.Context, items []domain.Item) error {
+	if len(items) == 0 {
+		return nil
 	}
 
-	data, err := json.Marshal(payload)
+	data, err := e.buildBody(items)
 	if err != nil {
-		return fmt.Errorf("failed to marshal payload: item=%s error=%w", item.ID, err)
+		return fmt.Errorf("failed to build digest payload: items=%d error=%w", len(items), err)
 	}
 
-	req, err := http.NewRequest("POST", e.config.Value, bytes.NewBuffer(data))
+	return e.post(ctx, data, items[0].ID, len(items))
+}
+
+// post sends a payload to the webhook and checks the response status
+func (e *WebhookExporter) post(ctx context.Context, data []byte, itemID string, count int) error {
+	resp, body, err := sendWithRateLimit(ctx, e.id, e.limiter, maxRateLimitRetries(e.config), func(ctx context.Context) (*http.Response, []byte, error) {
+		return e.send(ctx, data)
+	})
 	if err != nil {
-		return fmt.Errorf("failed to create request: item=%s error=%w", item.ID, err)
+		return fmt.Errorf("failed to send webhook: item=%s exporter=%s: %w", itemID, e.id, err)
 	}
 
-	req.Header.Set("Content-Type", "application/json")
+	if resp.StatusCode >= 400 {
+		return &domain.ExportError{
+			StatusCode: resp.StatusCode,
+			Err:        fmt.Errorf("webhook request failed: item=%s exporter=%s status=%d body=%s", itemID, e.id, resp.StatusCode, string(body)),
+		}
+	}
 
-	resp, err := e.client.Do(req)
+	logger.Info("Sent webhook: exporter=%s item=%s items=%d", e.id, itemID, count)
+	return nil
+}
+
+// send posts a payload to the webhook and returns the response with its body
+func (e *WebhookExporter) send(ctx context.Context, data []byte) (*http.Response, []byte, error) {
+	req, err := http.NewRequestWithContext(ctx, e.method, e.config.Value, bytes.NewReader(data))
 	if err != nil {
-		return fmt.Errorf("failed to send webhook: item=%s url=%s error=%w", item.ID, e.config.Value, err)
+		return nil, nil, fmt.Errorf("failed to create request: %w", err)
 	}
-	defer resp.Body.Close()
 
-	if resp.StatusCode >= 400 {
-		body, _ := io.ReadAll(resp.Body)
-		// Check for Discord rate limit response
-		if resp.StatusCode == 429 {
-			var rateLimitResp struct {
-				Message    string  `json:"message"`
-				RetryAfter float64 `json:"retry_after"`
-				Global     bool    `json:"global"`
-			}
-			if err := json.Unmarshal(body, &rateLimitResp); err == nil {
-				// Wait for the specified retry_after duration
-				retryDuration := time.Duration(rateLimitResp.RetryAfter * float64(time.Second))
-				logger.Debug("Rate limit hit: waiting for %v before retry", retryDuration)
-				time.Sleep(retryDuration)
-				// Retry the request
-				return e.Export(item)
-			}
+	req.Header.Set("Content-Type", e.contentType)
+	e.auth.apply(req, data)
+
+	resp, body, err := doRequest(e.client, req)
+	if err != nil {
+		// The webhook URL often embeds its credentials, keep it out of the error
+		var urlErr *url.Error
+		if errors.As(err, &urlErr) {
+			err = fmt.Errorf("%s request failed: %w", urlErr.Op, urlErr.Err)
 		}
-		return fmt.Errorf("webhook request failed: item=%s url=%s status=%d body=%s", item.ID, e.config.Value, resp.StatusCode, string(body))
+		return nil, nil, err
 	}
+	return resp, body, nil
+}
 
-	logger.Info("Sent webhook: url=%s item=%s", e.config.Value, item.ID)
-	return nil
+// GetID returns the exporter identifier used for deduplication
+func (e *WebhookExporter) GetID() string {
+	return e.id
 }
 
 // GetType returns the exporter type
 func (e *WebhookExporter) GetType() string {
-	return "webhook"
+	return e.exporterType
 }
 
 // GetGroup returns the group name
@@ -171,13 +231,75 @@ func (e *WebhookExporter) GetGroup() string {
 	return e.group
 }
 
+// GetRetryConfig returns the exporter-specific retry policy if configured
+func (e *WebhookExporter) GetRetryConfig() *config.RetryConfig {
+	return e.config.Retry
+}
+
+// GetDigestConfig returns the exporter digest configuration if configured
+func (e *WebhookExporter) GetDigestConfig() *config.DigestConfig {
+	return e.config.Digest
+}
+
+// buildBody renders the request body of one item, or of a digest when
+// several items are given, from the payload template if configured,
+// otherwise from the webhook format
+func (e *WebhookExporter) buildBody(items []domain.Item) ([]byte, error) {
+	if e.template == nil {
+		if len(items) == 1 {
+			return json.Marshal(e.createPayload(items[0]))
+		}
+		return json.Marshal(e.createDigestPayload(items))
+	}
+
+	var body bytes.Buffer
+	if err := e.template.Execute(&body, newTemplateData(items, e.group, e.id)); err != nil {
+		return nil, fmt.Errorf("failed to render template: %w", err)
+	}
+
+	// Catch broken templates before the receiver does
+	if strings.Contains(e.contentType, "json") && !json.Valid(body.Bytes()) {
+		return nil, fmt.Errorf("template rendered invalid JSON: %s", body.String())
+	}
+
+	return body.Bytes(), nil
+}
+
+// createPayload creates the payload matching the webhook format
+func (e *WebhookExporter) createPayload(item domain.Item) interface{} {
+	switch e.format {
+	case "discord":
+		return e.createDiscordPayload(item)
+	case "teams":
+		return e.createTeamsPayload(item)
+	case "slack":
+		return e.createSlackPayload(item)
+	default:
+		// Default to simple JSON payload
+		return item
+	}
+}
+
+// createDigestPayload creates the digest payload of several items matching
+// the webhook format
+func (e *WebhookExporter) createDigestPayload(items []domain.Item) interface{} {
+	switch e.format {
+	case "discord":
+		return e.createDiscordDigest(items)
+	case "teams":
+		return e.createTeamsDigest(items)
+	case "slack":
+		return e.createSlackDigest(items)
+	default:
+		return struct {
+			Items []domain.Item `json:"items"`
+		}{items}
+	}
+}
+
 // createDiscordPayload creates a Discord webhook payload
 func (e *WebhookExporter) createDiscordPayload(item domain.Item) DiscordWebhook {
-	// Extract domain from source URL
-	sourceDomain := "Unknown Source"
-	if parsedURL, err := url.Parse(item.Source); err == nil {
-		sourceDomain = strings.TrimPrefix(parsedURL.Hostname(), "www.")
-	}
+	sourceDomain := sourceName(item)
 
 	return DiscordWebhook{
 		Embeds: []DiscordEmbed{
@@ -197,11 +319,7 @@ func (e *WebhookExporter) createDiscordPayload(item domain.Item) DiscordWebhook
 
 // createTeamsPayload creates a Microsoft Teams webhook payload
 func (e *WebhookExporter) createTeamsPayload(item domain.Item) TeamsWebhook {
-	// Extract domain from source URL
-	sourceDomain := "Unknown Source"
-	if parsedURL, err := url.Parse(item.Source); err == nil {
-		sourceDomain = strings.TrimPrefix(parsedURL.Hostname(), "www.")
-	}
+	sourceDomain := sourceName(item)
 
 	return TeamsWebhook{
 		Type: "message",
@@ -239,4 +357,99 @@ func (e *WebhookExporter) createTeamsPayload(item domain.Item) TeamsWebhook {
 			},
 		},
 	}
-} 
\ No newline at end of file
+} 
+
+// createDiscordDigest creates a Discord webhook payload with one embed per
+// item, the items beyond the embed limit being listed in a last embed
+func (e *WebhookExporter) createDiscordDigest(items []domain.Item) DiscordWebhook {
+	embedded := items
+	var remaining []domain.Item
+	if len(items) > discordMaxEmbeds {
+		embedded = items[:discordMaxEmbeds-1]
+		remaining = items[discordMaxEmbeds-1:]
+	}
+
+	embeds := make([]DiscordEmbed, 0, discordMaxEmbeds)
+	for _, item := range embedded {
+		embeds = append(embeds, DiscordEmbed{
+			Title:       utils.Truncate(item.Title, discordMaxTitle),
+			Description: utils.Truncate(item.Description, discordDigestDescription),
+			URL:         item.Link,
+			Color:       3447003, // Blue color
+			Timestamp:   item.PublishedAt.Format(time.RFC3339),
+			Footer: &DiscordFooter{
+				Text: fmt.Sprintf("Source: %s", sourceName(item)),
+			},
+		})
+	}
+
+	if len(remaining) > 0 {
+		lines := make([]string, 0, len(remaining))
+		for _, item := range remaining {
+			lines = append(lines, fmt.Sprintf("• [%s](%s)", escapeMarkdown(item.Title), item.Link))
+		}
+		embeds = append(embeds, DiscordEmbed{
+			Title:       fmt.Sprintf("And %d more", len(remaining)),
+			Description: utils.Truncate(strings.Join(lines, "\n"), discordDigestOverflow),
+			Color:       3447003,
+		})
+	}
+
+	return DiscordWebhook{
+		Content: fmt.Sprintf("**%d new items**", len(items)),
+		Embeds:  embeds,
+	}
+}
+
+// createTeamsDigest creates a Microsoft Teams webhook payload listing several items
+func (e *WebhookExporter) createTeamsDigest(items []domain.Item) TeamsWebhook {
+	body := []TeamsBlock{
+		{
+			Type:   "TextBlock",
+			Text:   fmt.Sprintf("%d new items", len(items)),
+			Size:   "Large",
+			Weight: "Bolder",
+		},
+	}
+
+	for i, item := range items {
+		if i == teamsMaxDigestItems {
+			body = append(body, TeamsBlock{
+				Type: "TextBlock",
+				Text: fmt.Sprintf("And %d more", len(items)-i),
+				Size: "Small",
+			})
+			break
+		}
+		body = append(body, TeamsBlock{
+			Type: "TextBlock",
+			Text: fmt.Sprintf("- [%s](%s) (%s)", escapeMarkdown(item.Title), item.Link, sourceName(item)),
+			Wrap: true,
+		})
+	}
+
+	return TeamsWebhook{
+		Type: "message",
+		Attachments: []TeamsAttachment{
+			{
+				ContentType: "application/vnd.microsoft.card.adaptive",
+				ContentURL:  nil,
+				Content: TeamsContent{
+					Schema:  "http://adaptivecards.io/schemas/adaptive-card.json",
+					Type:    "AdaptiveCard",
+					Version: "1.2",
+					Body:    body,
+				},
+			},
+		},
+	}
+}
+
+// sourceName extracts a display name for the item source from its URL
+func sourceName(item domain.Item) string {
+	parsedURL, err := url.Parse(item.Source)
+	if err != nil || parsedURL.Hostname() == "" {
+		return "Unknown Source"
+	}
+	return strings.TrimPrefix(parsedURL.Hostname(), "www.")
+}
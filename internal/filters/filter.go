@@ -0,0 +1,159 @@
+package filters
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/leofvo/bridgr/internal/config"
+	"github.com/leofvo/bridgr/internal/domain"
+)
+
+// defaultFields are the item fields matched by rules without explicit fields
+var defaultFields = []string{config.FilterFieldTitle, config.FilterFieldDescription}
+
+// rule is a compiled filter rule
+type rule struct {
+	keywords []string
+	pattern  *regexp.Regexp
+	fields   []string
+}
+
+// Filter selects items according to include, exclude and age rules
+type Filter struct {
+	include []rule
+	exclude []rule
+	maxAge  time.Duration
+}
+
+// New compiles a filter configuration. A nil configuration returns a nil
+// filter, which lets every item through.
+func New(cfg *config.FilterConfig) (*Filter, error) {
+	if cfg == nil {
+		return nil, nil
+	}
+
+	include, err := compileRules(cfg.Include)
+	if err != nil {
+		return nil, fmt.Errorf("invalid include rule: %w", err)
+	}
+
+	exclude, err := compileRules(cfg.Exclude)
+	if err != nil {
+		return nil, fmt.Errorf("invalid exclude rule: %w", err)
+	}
+
+	return &Filter{
+		include: include,
+		exclude: exclude,
+		maxAge:  cfg.MaxAge,
+	}, nil
+}
+
+// Match reports whether an item passes the filter, with the reason it was
+// rejected otherwise
+func (f *Filter) Match(item domain.Item, now time.Time) (bool, string) {
+	if f == nil {
+		return true, ""
+	}
+
+	if f.maxAge > 0 && !item.PublishedAt.IsZero() && now.Sub(item.PublishedAt) > f.maxAge {
+		return false, fmt.Sprintf("older than %v", f.maxAge)
+	}
+
+	if len(f.include) > 0 {
+		included := false
+		for _, r := range f.include {
+			if r.match(item) {
+				included = true
+				break
+			}
+		}
+		if !included {
+			return false, "no include rule matched"
+		}
+	}
+
+	for _, r := range f.exclude {
+		if r.match(item) {
+			return false, fmt.Sprintf("matched exclude rule %s", r)
+		}
+	}
+
+	return true, ""
+}
+
+// compileRules compiles the patterns and normalizes the keywords of rules
+func compileRules(rules []config.FilterRule) ([]rule, error) {
+	compiled := make([]rule, 0, len(rules))
+	for _, r := range rules {
+		c := rule{fields: r.Fields}
+		if len(c.fields) == 0 {
+			c.fields = defaultFields
+		}
+
+		for _, keyword := range r.Keywords {
+			if keyword != "" {
+				c.keywords = append(c.keywords, strings.ToLower(keyword))
+			}
+		}
+
+		if r.Pattern != "" {
+			pattern, err := regexp.Compile(r.Pattern)
+			if err != nil {
+				return nil, fmt.Errorf("invalid pattern %q: %w", r.Pattern, err)
+			}
+			c.pattern = pattern
+		}
+
+		compiled = append(compiled, c)
+	}
+	return compiled, nil
+}
+
+// match reports whether any of the rule fields contains a keyword or matches
+// the pattern
+func (r rule) match(item domain.Item) bool {
+	for _, field := range r.fields {
+		for _, value := range fieldValues(item, field) {
+			lower := strings.ToLower(value)
+			for _, keyword := range r.keywords {
+				if strings.Contains(lower, keyword) {
+					return true
+				}
+			}
+			if r.pattern != nil && r.pattern.MatchString(value) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// String describes a rule in rejection reasons
+func (r rule) String() string {
+	var parts []string
+	if len(r.keywords) > 0 {
+		parts = append(parts, fmt.Sprintf("keywords=%s", strings.Join(r.keywords, ",")))
+	}
+	if r.pattern != nil {
+		parts = append(parts, fmt.Sprintf("pattern=%s", r.pattern))
+	}
+	return fmt.Sprintf("(%s on %s)", strings.Join(parts, " "), strings.Join(r.fields, ","))
+}
+
+// fieldValues returns the values of an item field
+func fieldValues(item domain.Item, field string) []string {
+	switch field {
+	case config.FilterFieldTitle:
+		return []string{item.Title}
+	case config.FilterFieldDescription:
+		return []string{item.Description}
+	case config.FilterFieldLink:
+		return []string{item.Link}
+	case config.FilterFieldCategories:
+		return item.Categories
+	}
+	return nil
+}
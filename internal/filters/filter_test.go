@@ -0,0 +1,128 @@
+package filters
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/leofvo/bridgr/internal/config"
+	"github.com/leofvo/bridgr/internal/domain"
+)
+
+var testNow = time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+
+var testItem = domain.Item{
+	ID:          "item-1",
+	Title:       "Kubernetes 1.30 released",
+	Description: "Highlights include sidecar containers",
+	Link:        "https://example.com/releases/k8s-1.30",
+	PublishedAt: testNow.Add(-2 * time.Hour),
+	Categories:  []string{"Release", "Cloud"},
+	Group:       "news",
+	SourceID:    "feed",
+}
+
+func TestFilterMatch(t *testing.T) {
+	tests := []struct {
+		name   string
+		config *config.FilterConfig
+		want   bool
+		reason string
+	}{
+		{
+			name:   "no filter",
+			config: nil,
+			want:   true,
+		},
+		{
+			name:   "include keyword is case-insensitive",
+			config: &config.FilterConfig{Include: []config.FilterRule{{Keywords: []string{"KUBERNETES"}}}},
+			want:   true,
+		},
+		{
+			name:   "include keyword in description",
+			config: &config.FilterConfig{Include: []config.FilterRule{{Keywords: []string{"sidecar"}}}},
+			want:   true,
+		},
+		{
+			name:   "no include rule matched",
+			config: &config.FilterConfig{Include: []config.FilterRule{{Keywords: []string{"postgres"}}}},
+			want:   false,
+			reason: "no include rule matched",
+		},
+		{
+			name: "any include rule matches",
+			config: &config.FilterConfig{Include: []config.FilterRule{
+				{Keywords: []string{"postgres"}},
+				{Pattern: `\d+\.\d+ released`},
+			}},
+			want: true,
+		},
+		{
+			name:   "include rule limited to other fields",
+			config: &config.FilterConfig{Include: []config.FilterRule{{Keywords: []string{"kubernetes"}, Fields: []string{config.FilterFieldLink}}}},
+			want:   false,
+			reason: "no include rule matched",
+		},
+		{
+			name:   "include on categories",
+			config: &config.FilterConfig{Include: []config.FilterRule{{Pattern: `^Cloud$`, Fields: []string{config.FilterFieldCategories}}}},
+			want:   true,
+		},
+		{
+			name:   "exclude keyword",
+			config: &config.FilterConfig{Exclude: []config.FilterRule{{Keywords: []string{"released"}}}},
+			want:   false,
+			reason: "matched exclude rule",
+		},
+		{
+			name:   "exclude pattern is case-sensitive",
+			config: &config.FilterConfig{Exclude: []config.FilterRule{{Pattern: `kubernetes`}}},
+			want:   true,
+		},
+		{
+			name: "exclude wins over include",
+			config: &config.FilterConfig{
+				Include: []config.FilterRule{{Keywords: []string{"kubernetes"}}},
+				Exclude: []config.FilterRule{{Pattern: `k8s`, Fields: []string{config.FilterFieldLink}}},
+			},
+			want:   false,
+			reason: "matched exclude rule",
+		},
+		{
+			name:   "within max age",
+			config: &config.FilterConfig{MaxAge: 3 * time.Hour},
+			want:   true,
+		},
+		{
+			name:   "older than max age",
+			config: &config.FilterConfig{MaxAge: time.Hour},
+			want:   false,
+			reason: "older than 1h0m0s",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			filter, err := New(tt.config)
+			if err != nil {
+				t.Fatalf("New() error = %v", err)
+			}
+
+			got, reason := filter.Match(testItem, testNow)
+			if got != tt.want {
+				t.Errorf("Match() = %v, want %v (reason %q)", got, tt.want, reason)
+			}
+			if !strings.HasPrefix(reason, tt.reason) {
+				t.Errorf("Match() reason = %q, want prefix %q", reason, tt.reason)
+			}
+		})
+	}
+}
+
+func TestNewInvalidPattern(t *testing.T) {
+	_, err := New(&config.FilterConfig{Exclude: []config.FilterRule{{Pattern: `(`}}})
+	if err == nil {
+		t.Fatal("New() error = nil, want an invalid pattern error")
+	}
+}
@@ -0,0 +1,81 @@
+package filters
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/leofvo/bridgr/internal/config"
+	"github.com/leofvo/bridgr/internal/domain"
+)
+
+// Set holds the filters configured at the group, source and exporter level.
+// An item is exported only if it passes the filters of all three levels.
+type Set struct {
+	groups    map[string]*Filter
+	sources   map[string]*Filter
+	exporters map[string]*Filter
+}
+
+// NewSet compiles the filters of a configuration, keyed by group name and
+// resolved source and exporter IDs
+func NewSet(cfg *config.Config) (*Set, error) {
+	s := &Set{
+		groups:    make(map[string]*Filter),
+		sources:   make(map[string]*Filter),
+		exporters: make(map[string]*Filter),
+	}
+
+	for _, group := range cfg.Groups {
+		filter, err := New(group.Filters)
+		if err != nil {
+			return nil, fmt.Errorf("failed to compile group filters: group=%s error=%w", group.Name, err)
+		}
+		if filter != nil {
+			s.groups[group.Name] = filter
+		}
+
+		for i := range group.Sources {
+			source := &group.Sources[i]
+			filter, err := New(source.Filters)
+			if err != nil {
+				return nil, fmt.Errorf("failed to compile source filters: url=%s error=%w", source.URL, err)
+			}
+			if filter != nil {
+				s.sources[source.ResolveID(group.Name)] = filter
+			}
+		}
+
+		for i := range group.Exporters {
+			exporter := &group.Exporters[i]
+			filter, err := New(exporter.Filters)
+			if err != nil {
+				return nil, fmt.Errorf("failed to compile exporter filters: type=%s error=%w", exporter.Type, err)
+			}
+			if filter != nil {
+				s.exporters[exporter.ResolveID(group.Name)] = filter
+			}
+		}
+	}
+
+	return s, nil
+}
+
+// Allow reports whether an item should be exported to an exporter, with the
+// reason it was filtered out otherwise
+func (s *Set) Allow(item domain.Item, exporterID string, now time.Time) (bool, string) {
+	if s == nil {
+		return true, ""
+	}
+
+	if ok, reason := s.groups[item.Group].Match(item, now); !ok {
+		return false, "group filter: " + reason
+	}
+	if ok, reason := s.sources[item.SourceID].Match(item, now); !ok {
+		return false, "source filter: " + reason
+	}
+	if ok, reason := s.exporters[exporterID].Match(item, now); !ok {
+		return false, "exporter filter: " + reason
+	}
+
+	return true, ""
+}
@@ -0,0 +1,61 @@
+package filters
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/leofvo/bridgr/internal/config"
+)
+
+func TestSetAllow(t *testing.T) {
+	cfg := &config.Config{
+		Groups: []config.GroupConfig{{
+			Name:    "news",
+			Filters: &config.FilterConfig{Exclude: []config.FilterRule{{Keywords: []string{"sponsored"}}}},
+			Sources: []config.SourceConfig{{
+				ID:      "feed",
+				Filters: &config.FilterConfig{Include: []config.FilterRule{{Keywords: []string{"kubernetes", "postgres"}}}},
+			}},
+			Exporters: []config.ExporterConfig{
+				{ID: "all"},
+				{ID: "postgres", Filters: &config.FilterConfig{Include: []config.FilterRule{{Keywords: []string{"postgres"}}}}},
+			},
+		}},
+	}
+
+	set, err := NewSet(cfg)
+	if err != nil {
+		t.Fatalf("NewSet() error = %v", err)
+	}
+
+	tests := []struct {
+		name     string
+		title    string
+		exporter string
+		want     bool
+		reason   string
+	}{
+		{name: "passes every level", title: "Kubernetes 1.30 released", exporter: "all", want: true},
+		{name: "group exclude", title: "Sponsored: Kubernetes training", exporter: "all", want: false, reason: "group filter: "},
+		{name: "source include", title: "Go 1.22 released", exporter: "all", want: false, reason: "source filter: "},
+		{name: "exporter include", title: "Kubernetes 1.30 released", exporter: "postgres", want: false, reason: "exporter filter: "},
+		{name: "exporter include matched", title: "Postgres 16 released", exporter: "postgres", want: true},
+		{name: "exporter without filters", title: "Postgres 16 released", exporter: "unknown", want: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			item := testItem
+			item.Title = tt.title
+			item.Description = ""
+
+			got, reason := set.Allow(item, tt.exporter, testNow)
+			if got != tt.want {
+				t.Errorf("Allow() = %v, want %v (reason %q)", got, tt.want, reason)
+			}
+			if !strings.HasPrefix(reason, tt.reason) {
+				t.Errorf("Allow() reason = %q, want prefix %q", reason, tt.reason)
+			}
+		})
+	}
+}
@@ -0,0 +1,134 @@
+package handlers
+
+import (
+	"context"
+	"crypto/subtle"
+	"encoding/json"
+	"net/http"
+	"strings"
+
+	"github.com/gorilla/mux"
+	"github.com/leofvo/bridgr/internal/domain"
+	"github.com/leofvo/bridgr/pkg/logger"
+)
+
+// Replayer re-enqueues dead-lettered deliveries
+type Replayer interface {
+	Replay(ctx context.Context, entry domain.DeadLetter) error
+}
+
+// ReplayResponse represents the result of a dead letter replay
+type ReplayResponse struct {
+	Replayed int      `json:"replayed"`
+	Errors   []string `json:"errors,omitempty"`
+}
+
+// DeadLetterHandler exposes the dead-letter store for inspection and replay
+type DeadLetterHandler struct {
+	store    domain.Store
+	replayer Replayer
+	token    string
+}
+
+// NewDeadLetterHandler creates a new dead letter handler, authenticating
+// requests with a bearer token
+func NewDeadLetterHandler(store domain.Store, replayer Replayer, token string) *DeadLetterHandler {
+	return &DeadLetterHandler{
+		store:    store,
+		replayer: replayer,
+		token:    token,
+	}
+}
+
+// Register registers the dead letter routes on the router
+func (h *DeadLetterHandler) Register(router *mux.Router) {
+	deadLetters := router.PathPrefix("/deadletters").Subrouter()
+	deadLetters.Use(h.authenticate)
+	deadLetters.HandleFunc("", h.List).Methods("GET")
+	deadLetters.HandleFunc("/replay", h.Replay).Methods("POST")
+	deadLetters.HandleFunc("/{id}/replay", h.Replay).Methods("POST")
+	deadLetters.HandleFunc("/{id}", h.Delete).Methods("DELETE")
+}
+
+// authenticate rejects the requests without the admin bearer token
+func (h *DeadLetterHandler) authenticate(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		token, ok := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer ")
+		if !ok || h.token == "" || subtle.ConstantTimeCompare([]byte(token), []byte(h.token)) != 1 {
+			w.Header().Set("WWW-Authenticate", "Bearer")
+			http.Error(w, "Unauthorized", http.StatusUnauthorized)
+			return
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+// List returns all dead letters
+func (h *DeadLetterHandler) List(w http.ResponseWriter, r *http.Request) {
+	entries, err := h.store.ListDeadLetters(r.Context())
+	if err != nil {
+		logger.Error("Failed to list dead letters: %v", err)
+		http.Error(w, "Internal Server Error", http.StatusInternalServerError)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, entries)
+}
+
+// Replay re-enqueues a single dead letter, or all of them when no ID is given
+func (h *DeadLetterHandler) Replay(w http.ResponseWriter, r *http.Request) {
+	entries, err := h.store.ListDeadLetters(r.Context())
+	if err != nil {
+		logger.Error("Failed to list dead letters: %v", err)
+		http.Error(w, "Internal Server Error", http.StatusInternalServerError)
+		return
+	}
+
+	id, single := mux.Vars(r)["id"]
+	response := ReplayResponse{}
+	found := false
+
+	for _, entry := range entries {
+		if single && entry.ID != id {
+			continue
+		}
+		found = true
+
+		if err := h.replayer.Replay(r.Context(), entry); err != nil {
+			logger.Error("Failed to replay dead letter: id=%s error=%v", entry.ID, err)
+			response.Errors = append(response.Errors, err.Error())
+			continue
+		}
+		response.Replayed++
+	}
+
+	if single && !found {
+		http.Error(w, "Not Found", http.StatusNotFound)
+		return
+	}
+
+	logger.Info("Replayed dead letters: count=%d errors=%d", response.Replayed, len(response.Errors))
+	writeJSON(w, http.StatusOK, response)
+}
+
+// Delete removes a dead letter without replaying it
+func (h *DeadLetterHandler) Delete(w http.ResponseWriter, r *http.Request) {
+	id := mux.Vars(r)["id"]
+	if err := h.store.RemoveDeadLetter(r.Context(), id); err != nil {
+		logger.Error("Failed to delete dead letter: id=%s error=%v", id, err)
+		http.Error(w, "Internal Server Error", http.StatusInternalServerError)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// writeJSON writes a JSON response
+func writeJSON(w http.ResponseWriter, status int, v interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+
+	if err := json.NewEncoder(w).Encode(v); err != nil {
+		logger.Error("Failed to encode response: %v", err)
+	}
+}
@@ -0,0 +1,175 @@
+package handlers
+
+import (
+	"context"
+	"net/http"
+	"time"
+
+	"github.com/leofvo/bridgr/internal/config"
+	"github.com/leofvo/bridgr/internal/domain"
+	"github.com/leofvo/bridgr/internal/services"
+)
+
+// Readiness statuses
+const (
+	statusOK          = "ok"
+	statusDegraded    = "degraded"
+	statusUnavailable = "unavailable"
+	statusPending     = "pending"
+	statusIdle        = "idle"
+)
+
+// storePingTimeout bounds the store check of a readiness request
+const storePingTimeout = 2 * time.Second
+
+// SourceHealthReporter reports the polling health of the sources
+type SourceHealthReporter interface {
+	Health() []services.SourceHealth
+}
+
+// ExporterStatusReporter reports the delivery status of the exporters
+type ExporterStatusReporter interface {
+	ExporterStatuses() []services.ExporterStatus
+}
+
+// ReadinessResponse represents the readiness check response
+type ReadinessResponse struct {
+	Status    string              `json:"status"`
+	Timestamp time.Time           `json:"timestamp"`
+	Store     StoreReadiness      `json:"store"`
+	Sources   []SourceReadiness   `json:"sources"`
+	Exporters []ExporterReadiness `json:"exporters"`
+}
+
+// StoreReadiness represents the status of the store
+type StoreReadiness struct {
+	Status  string  `json:"status"`
+	Latency float64 `json:"latency_ms"`
+	Error   string  `json:"error,omitempty"`
+}
+
+// SourceReadiness represents the status of a source
+type SourceReadiness struct {
+	Status string `json:"status"`
+	services.SourceHealth
+}
+
+// ExporterReadiness represents the status of an exporter
+type ExporterReadiness struct {
+	Status string `json:"status"`
+	services.ExporterStatus
+}
+
+// ReadinessHandler reports whether the instance can do its work: the store
+// must be reachable, and sources and exporters must not keep failing
+type ReadinessHandler struct {
+	store     domain.Store
+	sources   SourceHealthReporter
+	exporters ExporterStatusReporter
+	config    *config.ReadinessConfig
+}
+
+// NewReadinessHandler creates a new readiness handler
+func NewReadinessHandler(store domain.Store, sources SourceHealthReporter, exporters ExporterStatusReporter, cfg *config.ReadinessConfig) *ReadinessHandler {
+	return &ReadinessHandler{
+		store:     store,
+		sources:   sources,
+		exporters: exporters,
+		config:    cfg,
+	}
+}
+
+// ServeHTTP implements the http.Handler interface. It answers 503 when the
+// store is unreachable or every source is unhealthy, and when any source or
+// exporter is unhealthy if fail_on_degraded is set.
+func (h *ReadinessHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	now := time.Now()
+	response := ReadinessResponse{
+		Status:    statusOK,
+		Timestamp: now,
+		Store:     h.checkStore(r.Context()),
+	}
+
+	unhealthySources := 0
+	for _, health := range h.sources.Health() {
+		source := SourceReadiness{Status: h.sourceStatus(health, now), SourceHealth: health}
+		if source.Status == statusUnavailable {
+			unhealthySources++
+		}
+		response.Sources = append(response.Sources, source)
+	}
+
+	unhealthyExporters := 0
+	for _, status := range h.exporters.ExporterStatuses() {
+		exporter := ExporterReadiness{Status: h.exporterStatus(status), ExporterStatus: status}
+		if exporter.Status == statusUnavailable {
+			unhealthyExporters++
+		}
+		response.Exporters = append(response.Exporters, exporter)
+	}
+
+	switch {
+	case response.Store.Status != statusOK,
+		len(response.Sources) > 0 && unhealthySources == len(response.Sources):
+		response.Status = statusUnavailable
+	case unhealthySources > 0 || unhealthyExporters > 0:
+		response.Status = statusDegraded
+	}
+
+	code := http.StatusOK
+	if response.Status == statusUnavailable || (response.Status == statusDegraded && h.config.FailOnDegraded) {
+		code = http.StatusServiceUnavailable
+	}
+
+	writeJSON(w, code, response)
+}
+
+// checkStore pings the store
+func (h *ReadinessHandler) checkStore(ctx context.Context) StoreReadiness {
+	ctx, cancel := context.WithTimeout(ctx, storePingTimeout)
+	defer cancel()
+
+	start := time.Now()
+	err := h.store.Ping(ctx)
+	readiness := StoreReadiness{
+		Status:  statusOK,
+		Latency: float64(time.Since(start).Microseconds()) / 1000,
+	}
+	if err != nil {
+		readiness.Status = statusUnavailable
+		readiness.Error = err.Error()
+	}
+	return readiness
+}
+
+// sourceStatus returns the status of a source: unavailable once it has been
+// failing for longer than the threshold, degraded while it fails, pending
+// until its first poll
+func (h *ReadinessHandler) sourceStatus(health services.SourceHealth, now time.Time) string {
+	switch {
+	case health.ConsecutiveFailures > 0 && now.Sub(health.FailingSince) >= h.config.SourceFailureThreshold:
+		return statusUnavailable
+	case health.ConsecutiveFailures > 0:
+		return statusDegraded
+	case health.LastSuccess.IsZero():
+		return statusPending
+	default:
+		return statusOK
+	}
+}
+
+// exporterStatus returns the status of an exporter: unavailable after the
+// threshold of consecutive failed deliveries, degraded while deliveries
+// fail, idle until its first delivery
+func (h *ReadinessHandler) exporterStatus(status services.ExporterStatus) string {
+	switch {
+	case status.ConsecutiveFailures >= h.config.ExporterFailureThreshold:
+		return statusUnavailable
+	case status.ConsecutiveFailures > 0:
+		return statusDegraded
+	case status.LastDelivery.IsZero():
+		return statusIdle
+	default:
+		return statusOK
+	}
+}
@@ -0,0 +1,151 @@
+package metrics
+
+import (
+	"errors"
+	"strconv"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+
+	"github.com/leofvo/bridgr/internal/domain"
+)
+
+const namespace = "bridgr"
+
+var (
+	// SourceFetches counts source fetches by result
+	SourceFetches = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "source_fetches_total",
+		Help:      "Source fetches by source and result (success or failure).",
+	}, []string{"source", "result"})
+
+	// SourceFetchDuration observes the duration of source fetches
+	SourceFetchDuration = promauto.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Name:      "source_fetch_duration_seconds",
+		Help:      "Duration of source fetches.",
+		Buckets:   prometheus.DefBuckets,
+	}, []string{"source"})
+
+	// SourceItems counts the new items returned by source fetches
+	SourceItems = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "source_items_total",
+		Help:      "New items returned by source fetches.",
+	}, []string{"source"})
+
+	// SourceLastSuccess records the time of the last successful fetch of each source
+	SourceLastSuccess = promauto.NewGaugeVec(prometheus.GaugeOpts{
+		Namespace: namespace,
+		Name:      "source_last_success_timestamp_seconds",
+		Help:      "Unix time of the last successful fetch of a source.",
+	}, []string{"source"})
+
+	// Exports counts export attempts by result and status code
+	Exports = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "exports_total",
+		Help:      "Export attempts by exporter, result and status code.",
+	}, []string{"exporter", "result", "status_code"})
+
+	// ExportDuration observes the latency of export attempts
+	ExportDuration = promauto.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Name:      "export_duration_seconds",
+		Help:      "Latency of export attempts, including rate limit waits.",
+		Buckets:   prometheus.DefBuckets,
+	}, []string{"exporter"})
+
+	// ExportRetries counts the retries scheduled after failed exports
+	ExportRetries = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "export_retries_total",
+		Help:      "Retries scheduled after failed exports.",
+	}, []string{"exporter"})
+
+	// DeadLetters counts the deliveries given up and dead-lettered
+	DeadLetters = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "dead_letters_total",
+		Help:      "Deliveries given up and recorded as dead letters.",
+	}, []string{"exporter"})
+
+	// RateLimitHits counts the rate limited responses (429) received by exporters
+	RateLimitHits = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "rate_limit_hits_total",
+		Help:      "Rate limited responses (429 Too Many Requests) received by exporters.",
+	}, []string{"exporter"})
+
+	// RateLimitWaitDuration observes the time spent waiting for exporter rate limiters
+	RateLimitWaitDuration = promauto.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Name:      "rate_limit_wait_seconds",
+		Help:      "Time spent waiting for exporter rate limiters.",
+		Buckets:   []float64{0.001, 0.01, 0.1, 0.5, 1, 2, 5, 10, 30, 60},
+	}, []string{"exporter"})
+
+	// DedupHits counts the items skipped because an exporter already processed them
+	DedupHits = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "dedup_hits_total",
+		Help:      "Items skipped because the exporter already processed them.",
+	}, []string{"exporter"})
+
+	// StoreDuration observes the latency of store operations
+	StoreDuration = promauto.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Name:      "store_operation_duration_seconds",
+		Help:      "Latency of store operations.",
+		Buckets:   []float64{0.0005, 0.001, 0.0025, 0.005, 0.01, 0.025, 0.05, 0.1, 0.25, 0.5, 1},
+	}, []string{"operation"})
+)
+
+// RegisterQueueDepth exposes the number of pending deliveries
+func RegisterQueueDepth(depth func() int) {
+	promauto.NewGaugeFunc(prometheus.GaugeOpts{
+		Namespace: namespace,
+		Name:      "delivery_queue_depth",
+		Help:      "Pending deliveries, including scheduled retries.",
+	}, func() float64 {
+		return float64(depth())
+	})
+}
+
+// ObserveFetch records the outcome of a source fetch
+func ObserveFetch(sourceID string, start time.Time, items int, err error) {
+	SourceFetchDuration.WithLabelValues(sourceID).Observe(time.Since(start).Seconds())
+	if err != nil {
+		SourceFetches.WithLabelValues(sourceID, "failure").Inc()
+		return
+	}
+	SourceFetches.WithLabelValues(sourceID, "success").Inc()
+	SourceItems.WithLabelValues(sourceID).Add(float64(items))
+	SourceLastSuccess.WithLabelValues(sourceID).SetToCurrentTime()
+}
+
+// ObserveExport records the outcome of an export attempt. The status code is
+// the one reported by the exporter, "error" for failures without one (e.g.
+// network errors) and "ok" for successes.
+func ObserveExport(exporterID string, start time.Time, err error) {
+	ExportDuration.WithLabelValues(exporterID).Observe(time.Since(start).Seconds())
+	if err == nil {
+		Exports.WithLabelValues(exporterID, "success", "ok").Inc()
+		return
+	}
+
+	statusCode := "error"
+	var exportErr *domain.ExportError
+	if errors.As(err, &exportErr) && exportErr.StatusCode != 0 {
+		statusCode = strconv.Itoa(exportErr.StatusCode)
+	}
+	Exports.WithLabelValues(exporterID, "failure", statusCode).Inc()
+}
+
+// ObserveStore records the latency of a store operation, to be deferred at
+// the start of the operation
+func ObserveStore(operation string, start time.Time) {
+	StoreDuration.WithLabelValues(operation).Observe(time.Since(start).Seconds())
+}
@@ -1,6 +1,7 @@
 package ratelimit
 
 import (
+	"context"
 	"sync"
 	"time"
 
@@ -8,16 +9,24 @@ import (
 )
 
 // Limiter implements a token bucket rate limiter
+// A zero rate disables the token bucket, the limiter then only enforces pauses.
 type Limiter struct {
-	rate       float64
-	bucketSize float64
-	tokens     float64
-	lastRefill time.Time
-	mu         sync.Mutex
+	rate        float64
+	bucketSize  float64
+	tokens      float64
+	lastRefill  time.Time
+	pausedUntil time.Time
+	mu          sync.Mutex
 }
 
 // NewLimiter creates a new rate limiter with the specified requests per second
 func NewLimiter(requestsPerSecond float64) *Limiter {
+	if requestsPerSecond <= 0 {
+		return &Limiter{
+			lastRefill: time.Now(),
+		}
+	}
+
 	// Set bucket size to allow for some burst (1.5x the rate)
 	bucketSize := requestsPerSecond * 1.5
 	logger.Info("Initializing rate limiter: rate=%.2f req/s, bucket_size=%.2f", requestsPerSecond, bucketSize)
@@ -35,6 +44,13 @@ func (l *Limiter) Allow() bool {
 	defer l.mu.Unlock()
 
 	now := time.Now()
+	if now.Before(l.pausedUntil) {
+		return false
+	}
+	if l.rate <= 0 {
+		return true
+	}
+
 	elapsed := now.Sub(l.lastRefill).Seconds()
 	l.lastRefill = now
 
@@ -52,17 +68,34 @@ func (l *Limiter) Allow() bool {
 	return false
 }
 
-// Wait blocks until a request is allowed
-func (l *Limiter) Wait() {
+// Pause blocks all requests for the given duration, e.g. after the remote
+// service reported a rate limit. Overlapping pauses keep the latest deadline.
+func (l *Limiter) Pause(d time.Duration) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	until := time.Now().Add(d)
+	if until.After(l.pausedUntil) {
+		l.pausedUntil = until
+		logger.Info("Rate limit: pausing requests for %v", d)
+	}
+}
+
+// Wait blocks until a request is allowed or the context is cancelled
+func (l *Limiter) Wait(ctx context.Context) error {
 	start := time.Now()
 	attempts := 0
 
 	for !l.Allow() {
 		attempts++
-		// Calculate sleep duration based on rate
-		// Sleep for a fraction of the time between requests
-		sleepDuration := time.Duration(float64(time.Second) / l.rate / 5)
-		time.Sleep(sleepDuration)
+
+		timer := time.NewTimer(l.sleepDuration())
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return ctx.Err()
+		case <-timer.C:
+		}
 
 		// Log if we've been waiting for a while
 		if attempts%5 == 0 {
@@ -75,6 +108,24 @@ func (l *Limiter) Wait() {
 		logger.Info("Rate limit: request allowed after %v and %d attempts, rate: %.2f req/s", 
 			time.Since(start), attempts, l.rate)
 	}
+
+	return nil
+}
+
+// sleepDuration returns how long to sleep before checking the limiter again
+func (l *Limiter) sleepDuration() time.Duration {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if remaining := time.Until(l.pausedUntil); remaining > 0 {
+		return remaining
+	}
+	if l.rate <= 0 {
+		return 0
+	}
+
+	// Sleep for a fraction of the time between requests
+	return time.Duration(float64(time.Second) / l.rate / 5)
 }
 
 func min(a, b float64) float64 {
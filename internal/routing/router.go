@@ -0,0 +1,146 @@
+package routing
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/expr-lang/expr"
+	"github.com/expr-lang/expr/vm"
+
+	"github.com/leofvo/bridgr/internal/config"
+	"github.com/leofvo/bridgr/internal/domain"
+	"github.com/leofvo/bridgr/pkg/logger"
+)
+
+// Env is the data available to routing expressions
+type Env struct {
+	ID          string    `expr:"id"`
+	Title       string    `expr:"title"`
+	Description string    `expr:"description"`
+	Link        string    `expr:"link"`
+	Categories  []string  `expr:"categories"`
+	Source      string    `expr:"source"`
+	SourceURL   string    `expr:"source_url"`
+	Group       string    `expr:"group"`
+	PublishedAt time.Time `expr:"published_at"`
+}
+
+// newEnv builds the expression data of an item
+func newEnv(item domain.Item) Env {
+	return Env{
+		ID:          item.ID,
+		Title:       item.Title,
+		Description: item.Description,
+		Link:        item.Link,
+		Categories:  item.Categories,
+		Source:      item.SourceID,
+		SourceURL:   item.Source,
+		Group:       item.Group,
+		PublishedAt: item.PublishedAt,
+	}
+}
+
+// route is a compiled top-level route
+type route struct {
+	name      string
+	program   *vm.Program
+	exporters map[string]bool
+}
+
+// Router decides which exporters receive an item. An exporter receives the
+// items of its group matching its own condition, if any, and the items of
+// any group matching a route that targets it.
+type Router struct {
+	conditions map[string]*vm.Program
+	routes     []route
+}
+
+// NewRouter compiles the exporter conditions and routes of a configuration
+func NewRouter(cfg *config.Config) (*Router, error) {
+	r := &Router{
+		conditions: make(map[string]*vm.Program),
+	}
+
+	for _, group := range cfg.Groups {
+		for i := range group.Exporters {
+			exporter := &group.Exporters[i]
+			if exporter.When == "" {
+				continue
+			}
+
+			id := exporter.ResolveID(group.Name)
+			program, err := Compile(exporter.When)
+			if err != nil {
+				return nil, fmt.Errorf("invalid condition for exporter: id=%s error=%w", id, err)
+			}
+			r.conditions[id] = program
+		}
+	}
+
+	for _, routeCfg := range cfg.Routes {
+		program, err := Compile(routeCfg.When)
+		if err != nil {
+			return nil, fmt.Errorf("invalid condition for route: name=%s error=%w", routeCfg.Name, err)
+		}
+
+		exporters := make(map[string]bool, len(routeCfg.Exporters))
+		for _, id := range routeCfg.Exporters {
+			exporters[id] = true
+		}
+
+		r.routes = append(r.routes, route{
+			name:      routeCfg.Name,
+			program:   program,
+			exporters: exporters,
+		})
+	}
+
+	return r, nil
+}
+
+// Compile compiles a boolean routing expression
+func Compile(expression string) (*vm.Program, error) {
+	return expr.Compile(expression, expr.Env(Env{}), expr.AsBool())
+}
+
+// Exporters returns the exporters an item should be delivered to
+func (r *Router) Exporters(item domain.Item, exporters []domain.Exporter) []domain.Exporter {
+	env := newEnv(item)
+
+	routed := make(map[string]bool)
+	for _, rt := range r.routes {
+		if evaluate(rt.program, env, "route", rt.name, item) {
+			for id := range rt.exporters {
+				routed[id] = true
+			}
+		}
+	}
+
+	matched := make([]domain.Exporter, 0)
+	for _, exporter := range exporters {
+		id := exporter.GetID()
+		if routed[id] {
+			matched = append(matched, exporter)
+			continue
+		}
+		if exporter.GetGroup() != item.Group {
+			continue
+		}
+		if program, ok := r.conditions[id]; ok && !evaluate(program, env, "exporter", id, item) {
+			continue
+		}
+		matched = append(matched, exporter)
+	}
+
+	return matched
+}
+
+// evaluate runs a compiled condition, treating evaluation errors as no match
+func evaluate(program *vm.Program, env Env, kind, name string, item domain.Item) bool {
+	result, err := expr.Run(program, env)
+	if err != nil {
+		logger.Warn("Failed to evaluate %s condition: %s=%s item=%s error=%v", kind, kind, name, item.ID, err)
+		return false
+	}
+	return result.(bool)
+}
@@ -0,0 +1,126 @@
+package routing
+
+import (
+	"context"
+	"reflect"
+	"testing"
+
+	"github.com/leofvo/bridgr/internal/config"
+	"github.com/leofvo/bridgr/internal/domain"
+)
+
+// testExporter is an exporter only identified by its ID and group
+type testExporter struct {
+	id    string
+	group string
+}
+
+func (e testExporter) Export(ctx context.Context, item domain.Item) error { return nil }
+func (e testExporter) GetID() string                                      { return e.id }
+func (e testExporter) GetType() string                                    { return config.ExporterTypeWebhook }
+func (e testExporter) GetGroup() string                                   { return e.group }
+
+func TestRouterExporters(t *testing.T) {
+	cfg := &config.Config{
+		Groups: []config.GroupConfig{
+			{
+				Name: "news",
+				Exporters: []config.ExporterConfig{
+					{ID: "all"},
+					{ID: "security", When: `"security" in categories`},
+					{ID: "numeric", When: `int(id) > 0`},
+				},
+			},
+			{
+				Name:      "ops",
+				Exporters: []config.ExporterConfig{{ID: "pager"}},
+			},
+		},
+		Routes: []config.RouteConfig{
+			{Name: "critical", When: `title contains "CVE"`, Exporters: []string{"pager", "security"}},
+		},
+	}
+
+	router, err := NewRouter(cfg)
+	if err != nil {
+		t.Fatalf("NewRouter() error = %v", err)
+	}
+
+	exporters := []domain.Exporter{
+		testExporter{id: "all", group: "news"},
+		testExporter{id: "security", group: "news"},
+		testExporter{id: "numeric", group: "news"},
+		testExporter{id: "pager", group: "ops"},
+	}
+
+	tests := []struct {
+		name string
+		item domain.Item
+		want []string
+	}{
+		{
+			name: "group exporters without condition",
+			item: domain.Item{ID: "a", Title: "Weekly digest", Group: "news"},
+			want: []string{"all"},
+		},
+		{
+			name: "exporter condition matched",
+			item: domain.Item{ID: "a", Title: "Patch notes", Categories: []string{"security"}, Group: "news"},
+			want: []string{"all", "security"},
+		},
+		{
+			name: "numeric condition matched",
+			item: domain.Item{ID: "42", Title: "Weekly digest", Group: "news"},
+			want: []string{"all", "numeric"},
+		},
+		{
+			name: "route reaches exporters of other groups",
+			item: domain.Item{ID: "a", Title: "CVE-2024-1234 disclosed", Group: "news"},
+			want: []string{"all", "security", "pager"},
+		},
+		{
+			name: "route without group exporters",
+			item: domain.Item{ID: "a", Title: "CVE-2024-1234 disclosed", Group: "other"},
+			want: []string{"security", "pager"},
+		},
+		{
+			name: "other group",
+			item: domain.Item{ID: "a", Title: "Deploy finished", Group: "ops"},
+			want: []string{"pager"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := make([]string, 0)
+			for _, exporter := range router.Exporters(tt.item, exporters) {
+				got = append(got, exporter.GetID())
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Exporters() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestCompile(t *testing.T) {
+	tests := []struct {
+		name       string
+		expression string
+		wantErr    bool
+	}{
+		{name: "boolean", expression: `group == "news" && len(categories) > 0`},
+		{name: "unknown field", expression: `author == "me"`, wantErr: true},
+		{name: "not boolean", expression: `title`, wantErr: true},
+		{name: "syntax error", expression: `title ==`, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := Compile(tt.expression)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("Compile(%q) error = %v, wantErr %v", tt.expression, err, tt.wantErr)
+			}
+		})
+	}
+}
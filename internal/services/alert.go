@@ -0,0 +1,77 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/leofvo/bridgr/internal/domain"
+	"github.com/leofvo/bridgr/pkg/logger"
+)
+
+// alertFailing notifies the admin exporter once per failure streak when a
+// source has been failing for longer than the alert threshold
+func (s *SchedulerService) alertFailing(ctx context.Context, source domain.Source, health SourceHealth, now time.Time) {
+	_, polling, alerter := s.settings()
+	if alerter == nil || health.Alerted || now.Sub(health.FailingSince) < polling.Alert.After {
+		return
+	}
+
+	url := sourceURL(source)
+	s.sendAlert(ctx, alerter, domain.Item{
+		ID:    fmt.Sprintf("bridgr-alert:%s:%d", source.GetID(), health.FailingSince.Unix()),
+		Title: fmt.Sprintf("Source failing: %s", source.GetID()),
+		Description: fmt.Sprintf("Source %s (%s) in group %s has been failing since %s (%d consecutive failures). Last error: %s",
+			source.GetID(), url, source.GetGroup(), health.FailingSince.UTC().Format(time.RFC3339), health.ConsecutiveFailures, health.LastError),
+		Link:        url,
+		PublishedAt: now,
+		Source:      url,
+		SourceID:    source.GetID(),
+		Group:       source.GetGroup(),
+	})
+	s.health.MarkAlerted(source.GetID())
+}
+
+// alertRecovered notifies the admin exporter that a source reported as
+// failing is healthy again
+func (s *SchedulerService) alertRecovered(ctx context.Context, source domain.Source, previous SourceHealth, now time.Time) {
+	_, _, alerter := s.settings()
+	if alerter == nil || !previous.Alerted {
+		return
+	}
+
+	url := sourceURL(source)
+	s.sendAlert(ctx, alerter, domain.Item{
+		ID:    fmt.Sprintf("bridgr-alert:%s:%d:recovered", source.GetID(), previous.FailingSince.Unix()),
+		Title: fmt.Sprintf("Source recovered: %s", source.GetID()),
+		Description: fmt.Sprintf("Source %s (%s) in group %s recovered after failing for %v (%d consecutive failures).",
+			source.GetID(), url, source.GetGroup(), now.Sub(previous.FailingSince).Round(time.Second), previous.ConsecutiveFailures),
+		Link:        url,
+		PublishedAt: now,
+		Source:      url,
+		SourceID:    source.GetID(),
+		Group:       source.GetGroup(),
+	})
+}
+
+// sendAlert queues an alert for delivery through the admin exporter
+func (s *SchedulerService) sendAlert(ctx context.Context, alerter domain.Exporter, item domain.Item) {
+	enqueued, err := s.queue.Enqueue(ctx, item, alerter, nil)
+	if err != nil {
+		logger.Error("Failed to send source alert: item=%s exporter=%s error=%v", item.ID, alerter.GetID(), err)
+		return
+	}
+	if !enqueued {
+		logger.Debug("Alert already pending: item=%s", item.ID)
+		return
+	}
+	logger.Warn("Sent source alert: item=%s exporter=%s", item.ID, alerter.GetID())
+}
+
+// sourceURL returns the URL of a source, if it has one
+func sourceURL(source domain.Source) string {
+	if src, ok := source.(interface{ GetURL() string }); ok {
+		return src.GetURL()
+	}
+	return ""
+}
@@ -0,0 +1,44 @@
+package services
+
+import (
+	"sort"
+	"time"
+
+	"github.com/leofvo/bridgr/internal/config"
+	"github.com/leofvo/bridgr/internal/domain"
+)
+
+// splitBackfill splits the items of a first poll into the items to export and
+// the older items that must only be recorded as processed
+func splitBackfill(items []domain.Item, backfill *config.BackfillConfig, now time.Time) (export, seed []domain.Item) {
+	if backfill == nil {
+		return items, nil
+	}
+
+	switch backfill.Mode {
+	case config.BackfillNone:
+		return nil, items
+	case config.BackfillLast:
+		sorted := make([]domain.Item, len(items))
+		copy(sorted, items)
+		sort.SliceStable(sorted, func(i, j int) bool {
+			return sorted[i].PublishedAt.After(sorted[j].PublishedAt)
+		})
+		if len(sorted) <= backfill.Count {
+			return sorted, nil
+		}
+		return sorted[:backfill.Count], sorted[backfill.Count:]
+	case config.BackfillSince:
+		threshold := now.Add(-backfill.Since)
+		for _, item := range items {
+			if item.PublishedAt.Before(threshold) {
+				seed = append(seed, item)
+			} else {
+				export = append(export, item)
+			}
+		}
+		return export, seed
+	default:
+		return items, nil
+	}
+}
@@ -0,0 +1,565 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"math/rand"
+	"net/http"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/leofvo/bridgr/internal/config"
+	"github.com/leofvo/bridgr/internal/domain"
+	"github.com/leofvo/bridgr/internal/metrics"
+	"github.com/leofvo/bridgr/pkg/logger"
+)
+
+// delivery represents a pending export of an item, or of a batch of items
+// sent as a single notification, to an exporter
+type delivery struct {
+	items      []domain.Item
+	sourceTTLs map[string]*time.Duration
+	batch      bool
+	buffer     string
+	exporter   domain.Exporter
+	replayOf   string
+	attempts   int
+	lastErr    error
+}
+
+// newDelivery creates the delivery of buffered items to an exporter
+func newDelivery(entries []domain.BufferedItem, exporter domain.Exporter, buffer string) *delivery {
+	d := &delivery{
+		items:      make([]domain.Item, 0, len(entries)),
+		sourceTTLs: make(map[string]*time.Duration, len(entries)),
+		buffer:     buffer,
+		exporter:   exporter,
+	}
+	for _, entry := range entries {
+		d.items = append(d.items, entry.Item)
+		d.sourceTTLs[entry.Item.ID] = entry.SourceTTL
+	}
+	return d
+}
+
+// key identifies a delivery, an item is delivered at most once per exporter
+// and a buffer is flushed by at most one batch at a time
+func (d *delivery) key() string {
+	if d.batch && d.buffer != "" {
+		return d.exporter.GetID() + ":" + d.buffer
+	}
+	return d.exporter.GetID() + ":" + d.items[0].ID
+}
+
+// deadLetterID identifies the dead letter of a delivery
+func (d *delivery) deadLetterID() string {
+	if d.batch && d.buffer != "" {
+		return d.key() + ":" + d.items[0].ID
+	}
+	return d.key()
+}
+
+// export sends the delivery through its exporter
+func (d *delivery) export(ctx context.Context) error {
+	if !d.batch {
+		return d.exporter.Export(ctx, d.items[0])
+	}
+
+	batchExporter, ok := d.exporter.(domain.BatchExporter)
+	if !ok {
+		return fmt.Errorf("exporter does not support batches: exporter=%s", d.exporter.GetID())
+	}
+	return batchExporter.ExportBatch(ctx, d.items)
+}
+
+// DeliveryQueue delivers items to exporters with retries, and records
+// deliveries that keep failing in the dead-letter store
+type DeliveryQueue struct {
+	store     domain.Store
+	config    *config.DeliveryConfig
+	exporters map[string]domain.Exporter
+	jobs      chan *delivery
+	ctx       context.Context
+	cancel    context.CancelFunc
+	wg        sync.WaitGroup
+	mu        sync.Mutex
+	pending   map[string]*delivery
+	statuses  map[string]*ExporterStatus
+}
+
+// ExporterStatus describes the recent deliveries of an exporter
+type ExporterStatus struct {
+	ExporterID          string    `json:"exporter_id"`
+	LastDelivery        time.Time `json:"last_delivery,omitempty"`
+	LastFailure         time.Time `json:"last_failure,omitempty"`
+	LastError           string    `json:"last_error,omitempty"`
+	ConsecutiveFailures int       `json:"consecutive_failures"`
+}
+
+// NewDeliveryQueue creates a new delivery queue
+func NewDeliveryQueue(store domain.Store, cfg *config.DeliveryConfig, exporters []domain.Exporter) *DeliveryQueue {
+	byID := make(map[string]domain.Exporter, len(exporters))
+	statuses := make(map[string]*ExporterStatus, len(exporters))
+	for _, exporter := range exporters {
+		byID[exporter.GetID()] = exporter
+		statuses[exporter.GetID()] = &ExporterStatus{ExporterID: exporter.GetID()}
+	}
+
+	return &DeliveryQueue{
+		store:     store,
+		config:    cfg,
+		exporters: byID,
+		jobs:      make(chan *delivery, cfg.QueueSize),
+		pending:   make(map[string]*delivery),
+		statuses:  statuses,
+	}
+}
+
+// Start starts the delivery workers and queues again the deliveries left in
+// the store by a previous run
+func (q *DeliveryQueue) Start() {
+	q.ctx, q.cancel = context.WithCancel(context.Background())
+	for i := 0; i < q.config.Workers; i++ {
+		q.wg.Add(1)
+		go func() {
+			defer q.wg.Done()
+			q.work(q.ctx)
+		}()
+	}
+
+	q.wg.Add(1)
+	go func() {
+		defer q.wg.Done()
+		q.restore(q.ctx)
+	}()
+}
+
+// restore queues the deliveries of every exporter left in its store buffer
+func (q *DeliveryQueue) restore(ctx context.Context) {
+	q.mu.Lock()
+	exporters := make([]domain.Exporter, 0, len(q.exporters))
+	for _, exporter := range q.exporters {
+		exporters = append(exporters, exporter)
+	}
+	q.mu.Unlock()
+
+	for _, exporter := range exporters {
+		buffer := queueBuffer(exporter.GetID())
+		entries, err := q.store.ReadBuffer(ctx, buffer)
+		if err != nil {
+			logger.Error("Failed to restore queued deliveries: exporter=%s error=%v", exporter.GetID(), err)
+			continue
+		}
+
+		restored := 0
+		for _, entry := range entries {
+			if q.EnqueueBuffered(ctx, entry, exporter, buffer) {
+				restored++
+			}
+		}
+		if restored > 0 {
+			logger.Info("Restored queued deliveries: exporter=%s items=%d", exporter.GetID(), restored)
+		}
+	}
+}
+
+// Shutdown drains the pending deliveries until they are all done or the
+// context expires, then stops the workers. Deliveries still pending stay in
+// their store buffer and are queued again after restart; replays stay in the
+// dead-letter store.
+func (q *DeliveryQueue) Shutdown(ctx context.Context) {
+	ticker := time.NewTicker(100 * time.Millisecond)
+	defer ticker.Stop()
+
+drain:
+	for q.Depth() > 0 {
+		select {
+		case <-ctx.Done():
+			logger.Warn("Delivery drain deadline exceeded: pending=%d", q.Depth())
+			break drain
+		case <-ticker.C:
+		}
+	}
+
+	q.cancel()
+	q.wg.Wait()
+}
+
+// Enqueue schedules the delivery of an item to an exporter. The item is kept
+// in a store buffer until delivered or dead-lettered, so that the delivery
+// survives a restart. It returns false if the same delivery is already
+// pending, or if the context is cancelled while the queue is full.
+func (q *DeliveryQueue) Enqueue(ctx context.Context, item domain.Item, exporter domain.Exporter, sourceTTL *time.Duration) (bool, error) {
+	entry := domain.BufferedItem{Item: item, SourceTTL: sourceTTL}
+	buffer := queueBuffer(exporter.GetID())
+
+	if _, err := q.store.AppendBuffer(ctx, buffer, entry); err != nil {
+		return false, fmt.Errorf("failed to queue item: item=%s exporter=%s error=%w", item.ID, exporter.GetID(), err)
+	}
+
+	return q.EnqueueBuffered(ctx, entry, exporter, buffer), nil
+}
+
+// EnqueueBuffered schedules the delivery of an item read from a store buffer
+// to an exporter. Once delivered, the item is removed from the buffer. It
+// returns false if the same delivery is already pending, or if the context is
+// cancelled while the queue is full.
+func (q *DeliveryQueue) EnqueueBuffered(ctx context.Context, entry domain.BufferedItem, exporter domain.Exporter, buffer string) bool {
+	return q.enqueue(ctx, newDelivery([]domain.BufferedItem{entry}, exporter, buffer))
+}
+
+// EnqueueBatch schedules the delivery of items to an exporter as a single
+// notification. Once delivered, the items are removed from the given store
+// buffer. It returns false if a delivery of the same buffer is already
+// pending, or if the context is cancelled while the queue is full.
+func (q *DeliveryQueue) EnqueueBatch(ctx context.Context, entries []domain.BufferedItem, exporter domain.Exporter, buffer string) bool {
+	if len(entries) == 0 {
+		return false
+	}
+
+	d := newDelivery(entries, exporter, buffer)
+	d.batch = true
+	return q.enqueue(ctx, d)
+}
+
+// enqueue registers a delivery as pending and hands it to the workers. A
+// delivery the caller gave up on is dropped, so that it can be queued again.
+func (q *DeliveryQueue) enqueue(ctx context.Context, d *delivery) bool {
+	q.mu.Lock()
+	if _, exists := q.pending[d.key()]; exists {
+		q.mu.Unlock()
+		return false
+	}
+	q.pending[d.key()] = d
+	q.mu.Unlock()
+
+	if !q.submit(ctx, d) {
+		q.done(d)
+		return false
+	}
+	return true
+}
+
+// Depth returns the number of pending deliveries, including scheduled retries
+func (q *DeliveryQueue) Depth() int {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	return len(q.pending)
+}
+
+// SetExporters replaces the exporters available for replays and status
+// reporting. Queued deliveries keep the exporter instance they were queued with.
+func (q *DeliveryQueue) SetExporters(exporters []domain.Exporter) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	byID := make(map[string]domain.Exporter, len(exporters))
+	statuses := make(map[string]*ExporterStatus, len(exporters))
+	for _, exporter := range exporters {
+		byID[exporter.GetID()] = exporter
+		if status, ok := q.statuses[exporter.GetID()]; ok {
+			statuses[exporter.GetID()] = status
+		} else {
+			statuses[exporter.GetID()] = &ExporterStatus{ExporterID: exporter.GetID()}
+		}
+	}
+
+	q.exporters = byID
+	q.statuses = statuses
+}
+
+// ExporterStatuses returns the delivery status of every exporter
+func (q *DeliveryQueue) ExporterStatuses() []ExporterStatus {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	statuses := make([]ExporterStatus, 0, len(q.statuses))
+	for _, status := range q.statuses {
+		statuses = append(statuses, *status)
+	}
+	sort.Slice(statuses, func(i, j int) bool {
+		return statuses[i].ExporterID < statuses[j].ExporterID
+	})
+	return statuses
+}
+
+// Replay re-enqueues a dead letter. It is removed from the dead-letter store
+// once the replayed delivery succeeds or is dead-lettered again.
+func (q *DeliveryQueue) Replay(ctx context.Context, entry domain.DeadLetter) error {
+	q.mu.Lock()
+	exporter, ok := q.exporters[entry.ExporterID]
+	q.mu.Unlock()
+	if !ok {
+		return fmt.Errorf("unknown exporter: %s", entry.ExporterID)
+	}
+
+	items := []domain.Item{entry.Item}
+	if len(entry.Items) > 0 {
+		items = entry.Items
+	}
+	entries := make([]domain.BufferedItem, 0, len(items))
+	for _, item := range items {
+		buffered := domain.BufferedItem{Item: item}
+		if ttl, ok := entry.SourceTTLs[item.ID]; ok {
+			buffered.SourceTTL = &ttl
+		}
+		entries = append(entries, buffered)
+	}
+
+	d := newDelivery(entries, exporter, "")
+	d.batch = len(entry.Items) > 0
+	d.replayOf = entry.ID
+	if !q.enqueue(ctx, d) {
+		if ctx.Err() != nil {
+			return fmt.Errorf("failed to replay dead letter: id=%s error=%w", entry.ID, ctx.Err())
+		}
+		logger.Debug("Dead letter already pending: id=%s", entry.ID)
+	}
+	return nil
+}
+
+// submit hands a delivery to the workers, waiting while the queue is full.
+// It returns false if the context is cancelled first.
+func (q *DeliveryQueue) submit(ctx context.Context, d *delivery) bool {
+	select {
+	case q.jobs <- d:
+		return true
+	case <-q.ctx.Done():
+		// Kept in the store, see Shutdown
+		return true
+	case <-ctx.Done():
+		return false
+	}
+}
+
+// work processes deliveries until the context is cancelled
+func (q *DeliveryQueue) work(ctx context.Context) {
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case d := <-q.jobs:
+			q.attempt(ctx, d)
+		}
+	}
+}
+
+// attempt exports a delivery once and schedules a retry on failure
+func (q *DeliveryQueue) attempt(ctx context.Context, d *delivery) {
+	// Bookkeeping must complete even if the export was cut short by shutdown
+	storeCtx := context.WithoutCancel(ctx)
+
+	d.attempts++
+	start := time.Now()
+	err := d.export(ctx)
+	metrics.ObserveExport(d.exporter.GetID(), start, err)
+	q.recordAttempt(d.exporter.GetID(), err)
+	if err == nil {
+		if err := q.markProcessed(storeCtx, d); err != nil {
+			logger.Error("Failed to mark item as processed: item=%s exporter=%s error=%v", d.items[0].ID, d.exporter.GetID(), err)
+		}
+		q.removeReplayed(storeCtx, d, "")
+		q.done(d)
+		logger.Info("Processed item: item=%s items=%d exporter=%s attempts=%d", d.items[0].ID, len(d.items), d.exporter.GetID(), d.attempts)
+		return
+	}
+
+	d.lastErr = err
+	if ctx.Err() != nil {
+		// Kept in the store, see Shutdown
+		return
+	}
+
+	policy := q.retryPolicy(d.exporter)
+	if !isRetryable(err, policy) || d.attempts >= policy.MaxAttempts {
+		logger.Error("Failed to export item, giving up: item=%s exporter=%s attempts=%d error=%v", d.items[0].ID, d.exporter.GetID(), d.attempts, err)
+		q.deadLetter(storeCtx, d)
+		return
+	}
+
+	metrics.ExportRetries.WithLabelValues(d.exporter.GetID()).Inc()
+	backoff := retryBackoff(policy, d.attempts, err)
+	logger.Warn("Failed to export item, retrying: item=%s exporter=%s attempt=%d backoff=%v error=%v", d.items[0].ID, d.exporter.GetID(), d.attempts, backoff, err)
+
+	go func() {
+		timer := time.NewTimer(backoff)
+		defer timer.Stop()
+
+		select {
+		case <-ctx.Done():
+			// Kept in the store, see Shutdown
+		case <-timer.C:
+			q.submit(ctx, d)
+		}
+	}()
+}
+
+// deadLetter records a failed delivery and marks the item as processed so it
+// is not picked up again until the dead letter is replayed
+func (q *DeliveryQueue) deadLetter(ctx context.Context, d *delivery) {
+	entry := &domain.DeadLetter{
+		ID:         d.deadLetterID(),
+		Item:       d.items[0],
+		ExporterID: d.exporter.GetID(),
+		Attempts:   d.attempts,
+		FailedAt:   time.Now(),
+	}
+	if d.batch {
+		entry.Items = d.items
+	}
+	for id, ttl := range d.sourceTTLs {
+		if ttl == nil {
+			continue
+		}
+		if entry.SourceTTLs == nil {
+			entry.SourceTTLs = make(map[string]time.Duration)
+		}
+		entry.SourceTTLs[id] = *ttl
+	}
+	if d.lastErr != nil {
+		entry.LastError = d.lastErr.Error()
+	}
+	var exportErr *domain.ExportError
+	if errors.As(d.lastErr, &exportErr) {
+		entry.StatusCode = exportErr.StatusCode
+	}
+
+	if err := q.store.AddDeadLetter(ctx, entry); err != nil {
+		logger.Error("Failed to add dead letter: item=%s exporter=%s error=%v", d.items[0].ID, d.exporter.GetID(), err)
+		q.done(d)
+		return
+	}
+
+	if err := q.markProcessed(ctx, d); err != nil {
+		logger.Error("Failed to mark dead-lettered item as processed: item=%s exporter=%s error=%v", d.items[0].ID, d.exporter.GetID(), err)
+	}
+	q.removeReplayed(ctx, d, entry.ID)
+
+	q.done(d)
+	metrics.DeadLetters.WithLabelValues(d.exporter.GetID()).Inc()
+	logger.Warn("Dead-lettered item: item=%s exporter=%s attempts=%d", d.items[0].ID, d.exporter.GetID(), d.attempts)
+}
+
+// markProcessed marks the items of a delivery as processed by its exporter
+// and removes them from their buffer
+func (q *DeliveryQueue) markProcessed(ctx context.Context, d *delivery) error {
+	ids := make([]string, 0, len(d.items))
+	for _, item := range d.items {
+		if err := q.store.MarkProcessed(ctx, item.ID, d.exporter.GetID(), d.sourceTTLs[item.ID]); err != nil {
+			return err
+		}
+		ids = append(ids, item.ID)
+	}
+
+	if d.buffer != "" {
+		return q.store.RemoveFromBuffer(ctx, d.buffer, ids)
+	}
+	return nil
+}
+
+// removeReplayed removes the dead letter replayed by a delivery, unless it
+// was just recorded again under the same ID
+func (q *DeliveryQueue) removeReplayed(ctx context.Context, d *delivery, keep string) {
+	if d.replayOf == "" || d.replayOf == keep {
+		return
+	}
+	if err := q.store.RemoveDeadLetter(ctx, d.replayOf); err != nil {
+		logger.Error("Failed to remove replayed dead letter: id=%s error=%v", d.replayOf, err)
+	}
+}
+
+// recordAttempt updates the delivery status of an exporter after an attempt
+func (q *DeliveryQueue) recordAttempt(exporterID string, err error) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	status, ok := q.statuses[exporterID]
+	if !ok {
+		status = &ExporterStatus{ExporterID: exporterID}
+		q.statuses[exporterID] = status
+	}
+
+	if err == nil {
+		status.LastDelivery = time.Now()
+		status.ConsecutiveFailures = 0
+		return
+	}
+	status.LastFailure = time.Now()
+	status.LastError = err.Error()
+	status.ConsecutiveFailures++
+}
+
+// queueBuffer returns the name of the store buffer holding the queued items
+// of an exporter until they are delivered
+func queueBuffer(exporterID string) string {
+	return "queue:" + exporterID
+}
+
+// done removes a delivery from the pending set
+func (q *DeliveryQueue) done(d *delivery) {
+	q.mu.Lock()
+	delete(q.pending, d.key())
+	q.mu.Unlock()
+}
+
+// retryPolicy returns the retry policy of an exporter, falling back to the
+// delivery default
+func (q *DeliveryQueue) retryPolicy(exporter domain.Exporter) *config.RetryConfig {
+	if e, ok := exporter.(interface{ GetRetryConfig() *config.RetryConfig }); ok {
+		if policy := e.GetRetryConfig(); policy != nil {
+			return policy
+		}
+	}
+	return &q.config.Retry
+}
+
+// isRetryable reports whether an export error should be retried. Errors
+// without a status code (e.g. network failures, timeouts) and server errors
+// are retried unless marked permanent, client errors only when listed by the
+// policy or when asking to try again later (408, 429).
+func isRetryable(err error, policy *config.RetryConfig) bool {
+	var exportErr *domain.ExportError
+	if !errors.As(err, &exportErr) {
+		return true
+	}
+	if exportErr.Permanent {
+		return false
+	}
+	if exportErr.StatusCode == 0 {
+		return true
+	}
+
+	statusCode := exportErr.StatusCode
+	if policy.IsRetryableStatus(statusCode) {
+		return true
+	}
+	switch {
+	case statusCode == http.StatusRequestTimeout, statusCode == http.StatusTooManyRequests:
+		return true
+	case statusCode >= 400 && statusCode < 500:
+		return false
+	default:
+		return true
+	}
+}
+
+// retryBackoff computes the exponential backoff with jitter before the next
+// attempt, honoring any delay requested by the exporter
+func retryBackoff(policy *config.RetryConfig, attempts int, err error) time.Duration {
+	backoff := float64(policy.InitialBackoff) * math.Pow(policy.Multiplier, float64(attempts-1))
+	backoff = math.Min(backoff, float64(policy.MaxBackoff))
+	if policy.Jitter != nil {
+		backoff += backoff * *policy.Jitter * (rand.Float64()*2 - 1)
+	}
+
+	delay := time.Duration(backoff)
+	var exportErr *domain.ExportError
+	if errors.As(err, &exportErr) && exportErr.RetryAfter > delay {
+		delay = exportErr.RetryAfter
+	}
+
+	return delay
+}
@@ -0,0 +1,225 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/leofvo/bridgr/internal/config"
+	"github.com/leofvo/bridgr/internal/domain"
+	"github.com/leofvo/bridgr/internal/window"
+	"github.com/leofvo/bridgr/pkg/logger"
+)
+
+// digestExporter is an exporter sending its items as periodic digests
+type digestExporter struct {
+	exporter domain.BatchExporter
+	config   *config.DigestConfig
+	buffer   string
+	window   *window.Window
+	flush    chan struct{}
+}
+
+// DigestService buffers the items of digest exporters in the store and
+// flushes them as a single notification on a schedule or size threshold.
+// Digests due while the delivery window of their exporter is closed are sent
+// when it opens.
+type DigestService struct {
+	store     domain.Store
+	queue     *DeliveryQueue
+	runners   *runners
+	mu        sync.RWMutex
+	exporters map[string]*digestExporter
+}
+
+// NewDigestService creates a new digest service for the exporters configured
+// with a digest
+func NewDigestService(store domain.Store, queue *DeliveryQueue, windows *window.Set, exporters []domain.Exporter) *DigestService {
+	return &DigestService{
+		store:     store,
+		queue:     queue,
+		runners:   newRunners(),
+		exporters: newDigestExporters(windows, exporters),
+	}
+}
+
+// newDigestExporters returns the exporters configured with a digest, by ID
+func newDigestExporters(windows *window.Set, exporters []domain.Exporter) map[string]*digestExporter {
+	digests := make(map[string]*digestExporter)
+
+	for _, exporter := range exporters {
+		e, ok := exporter.(interface{ GetDigestConfig() *config.DigestConfig })
+		if !ok || e.GetDigestConfig() == nil {
+			continue
+		}
+
+		batchExporter, ok := exporter.(domain.BatchExporter)
+		if !ok {
+			logger.Warn("Exporter does not support digests, sending items individually: exporter=%s type=%s", exporter.GetID(), exporter.GetType())
+			continue
+		}
+
+		digests[exporter.GetID()] = &digestExporter{
+			exporter: batchExporter,
+			config:   e.GetDigestConfig(),
+			buffer:   digestBuffer(exporter.GetID()),
+			window:   windows.Get(exporter.GetID()),
+			flush:    make(chan struct{}, 1),
+		}
+	}
+
+	return digests
+}
+
+// Start starts flushing the digests on their schedule
+func (s *DigestService) Start(ctx context.Context) {
+	s.runners.setContext(ctx)
+
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	for _, d := range s.exporters {
+		s.startDigest(d)
+	}
+}
+
+// Stop waits for the digest schedules to stop. Buffered items are kept in
+// the store and flushed after restart.
+func (s *DigestService) Stop() {
+	s.runners.wait()
+}
+
+// Update replaces the digest exporters. Only the schedules of exporters that
+// were added, removed or replaced by a new instance are restarted; buffered
+// items are kept in the store.
+func (s *DigestService) Update(windows *window.Set, exporters []domain.Exporter) {
+	updated := newDigestExporters(windows, exporters)
+
+	s.mu.Lock()
+	previous := s.exporters
+	for id, d := range previous {
+		// An unchanged exporter keeps its schedule and pending flush requests
+		if u, ok := updated[id]; ok && u.exporter == d.exporter {
+			updated[id] = d
+		}
+	}
+	s.exporters = updated
+	s.mu.Unlock()
+
+	for id, d := range previous {
+		if updated[id] != d {
+			s.runners.stop(id)
+		}
+	}
+	for id, d := range updated {
+		if previous[id] != d {
+			s.startDigest(d)
+		}
+	}
+}
+
+// startDigest starts the schedule of a digest in its own goroutine
+func (s *DigestService) startDigest(d *digestExporter) {
+	s.runners.start(d.exporter.GetID(), func(ctx context.Context) {
+		s.scheduleDigest(ctx, d)
+	})
+}
+
+// Handles reports whether an exporter sends its items as digests
+func (s *DigestService) Handles(exporter domain.Exporter) bool {
+	_, ok := s.digest(exporter.GetID())
+	return ok
+}
+
+// digest returns the digest of an exporter
+func (s *DigestService) digest(exporterID string) (*digestExporter, bool) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	d, ok := s.exporters[exporterID]
+	return d, ok
+}
+
+// Add buffers an item, with the TTL of its source, for the next digest of an
+// exporter, triggering a flush once the buffer reaches the configured size
+func (s *DigestService) Add(ctx context.Context, item domain.Item, exporter domain.Exporter, sourceTTL *time.Duration) error {
+	d, ok := s.digest(exporter.GetID())
+	if !ok {
+		return fmt.Errorf("exporter has no digest: exporter=%s", exporter.GetID())
+	}
+
+	entry := domain.BufferedItem{Item: item, SourceTTL: sourceTTL}
+	count, err := s.store.AppendBuffer(ctx, d.buffer, entry)
+	if err != nil {
+		return fmt.Errorf("failed to buffer item: item=%s exporter=%s error=%w", item.ID, exporter.GetID(), err)
+	}
+	logger.Debug("Buffered item for digest: item=%s exporter=%s buffered=%d", item.ID, exporter.GetID(), count)
+
+	if d.config.MaxItems > 0 && count >= d.config.MaxItems {
+		select {
+		case d.flush <- struct{}{}:
+		default:
+			// A flush is already requested
+		}
+	}
+
+	return nil
+}
+
+// scheduleDigest flushes a digest every interval and whenever its buffer is full
+func (s *DigestService) scheduleDigest(ctx context.Context, d *digestExporter) {
+	ticker := time.NewTicker(d.config.Interval)
+	defer ticker.Stop()
+
+	// Checks whether a digest deferred by a closed window can be sent
+	windowTicker := time.NewTicker(windowCheckInterval)
+	defer windowTicker.Stop()
+	deferred := false
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		case <-d.flush:
+		case <-windowTicker.C:
+			if !deferred {
+				continue
+			}
+		}
+
+		deferred = !d.window.Open(time.Now())
+		if deferred {
+			logger.Debug("Digest deferred until the delivery window opens: exporter=%s", d.exporter.GetID())
+			continue
+		}
+
+		if err := s.flushDigest(ctx, d); err != nil {
+			logger.Error("Failed to flush digest: exporter=%s error=%v", d.exporter.GetID(), err)
+		}
+	}
+}
+
+// flushDigest queues the buffered items of a digest as a single delivery
+func (s *DigestService) flushDigest(ctx context.Context, d *digestExporter) error {
+	entries, err := s.store.ReadBuffer(ctx, d.buffer)
+	if err != nil {
+		return err
+	}
+	if len(entries) == 0 {
+		return nil
+	}
+
+	// The delivery queue removes the items from the buffer once delivered
+	if !s.queue.EnqueueBatch(ctx, entries, d.exporter, d.buffer) {
+		logger.Debug("Digest delivery already pending: exporter=%s", d.exporter.GetID())
+		return nil
+	}
+
+	logger.Info("Flushed digest: exporter=%s items=%d", d.exporter.GetID(), len(entries))
+	return nil
+}
+
+// digestBuffer returns the name of the store buffer holding the digest of an exporter
+func digestBuffer(exporterID string) string {
+	return "digest:" + exporterID
+}
@@ -0,0 +1,168 @@
+package services
+
+import (
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/leofvo/bridgr/internal/domain"
+)
+
+const (
+	// maxPublishTimes bounds the publish times kept to estimate the frequency of a source
+	maxPublishTimes = 20
+
+	// adaptivePollsPerItem is how many polls happen per expected publish interval
+	adaptivePollsPerItem = 2
+)
+
+// SourceHealth describes the polling health of a source
+type SourceHealth struct {
+	SourceID            string    `json:"source_id"`
+	ConsecutiveFailures int       `json:"consecutive_failures"`
+	FailingSince        time.Time `json:"failing_since,omitempty"`
+	LastError           string    `json:"last_error,omitempty"`
+	LastSuccess         time.Time `json:"last_success,omitempty"`
+	NextPoll            time.Time `json:"next_poll,omitempty"`
+	Alerted             bool      `json:"alerted"`
+	published           []time.Time
+}
+
+// HealthTracker tracks the polling health of the sources
+type HealthTracker struct {
+	mu      sync.Mutex
+	sources map[string]*SourceHealth
+}
+
+// NewHealthTracker creates a new health tracker
+func NewHealthTracker() *HealthTracker {
+	return &HealthTracker{
+		sources: make(map[string]*SourceHealth),
+	}
+}
+
+// RecordSuccess records a successful poll and returns the health of the
+// source before the poll, to detect recoveries
+func (t *HealthTracker) RecordSuccess(sourceID string, now time.Time) SourceHealth {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	health := t.get(sourceID)
+	previous := *health
+
+	health.ConsecutiveFailures = 0
+	health.FailingSince = time.Time{}
+	health.LastError = ""
+	health.LastSuccess = now
+	health.Alerted = false
+
+	return previous
+}
+
+// RecordFailure records a failed poll and returns the updated health of the source
+func (t *HealthTracker) RecordFailure(sourceID string, err error, now time.Time) SourceHealth {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	health := t.get(sourceID)
+	if health.ConsecutiveFailures == 0 {
+		health.FailingSince = now
+	}
+	health.ConsecutiveFailures++
+	health.LastError = err.Error()
+
+	return *health
+}
+
+// RecordPublished records the publish times of the items returned by a poll
+func (t *HealthTracker) RecordPublished(sourceID string, items []domain.Item) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	health := t.get(sourceID)
+	for _, item := range items {
+		if !item.PublishedAt.IsZero() {
+			health.published = append(health.published, item.PublishedAt)
+		}
+	}
+
+	sort.Slice(health.published, func(i, j int) bool {
+		return health.published[i].Before(health.published[j])
+	})
+	if len(health.published) > maxPublishTimes {
+		health.published = health.published[len(health.published)-maxPublishTimes:]
+	}
+}
+
+// MarkAlerted records that an alert was sent for the current failure streak
+func (t *HealthTracker) MarkAlerted(sourceID string) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.get(sourceID).Alerted = true
+}
+
+// SetNextPoll records the time of the next poll of a source
+func (t *HealthTracker) SetNextPoll(sourceID string, next time.Time) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.get(sourceID).NextPoll = next
+}
+
+// Get returns the health of a source
+func (t *HealthTracker) Get(sourceID string) SourceHealth {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return *t.get(sourceID)
+}
+
+// Remove stops tracking the health of a source
+func (t *HealthTracker) Remove(sourceID string) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	delete(t.sources, sourceID)
+}
+
+// Snapshot returns the health of every tracked source
+func (t *HealthTracker) Snapshot() []SourceHealth {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	snapshot := make([]SourceHealth, 0, len(t.sources))
+	for _, health := range t.sources {
+		snapshot = append(snapshot, *health)
+	}
+	sort.Slice(snapshot, func(i, j int) bool {
+		return snapshot[i].SourceID < snapshot[j].SourceID
+	})
+	return snapshot
+}
+
+// PublishInterval estimates the interval between the items of a source from
+// their publish times, counting the time elapsed since the last item so that
+// quiet sources are polled less often
+func (t *HealthTracker) PublishInterval(sourceID string, now time.Time) (time.Duration, bool) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	published := t.get(sourceID).published
+	if len(published) < 2 {
+		return 0, false
+	}
+
+	last := published[len(published)-1]
+	interval := last.Sub(published[0]) / time.Duration(len(published)-1)
+	if elapsed := now.Sub(last); elapsed > interval {
+		interval = elapsed
+	}
+	return interval, true
+}
+
+// get returns the health of a source, creating it if needed
+func (t *HealthTracker) get(sourceID string) *SourceHealth {
+	health, ok := t.sources[sourceID]
+	if !ok {
+		health = &SourceHealth{SourceID: sourceID}
+		t.sources[sourceID] = health
+	}
+	return health
+}
@@ -0,0 +1,177 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/leofvo/bridgr/internal/domain"
+	"github.com/leofvo/bridgr/internal/window"
+	"github.com/leofvo/bridgr/pkg/logger"
+)
+
+// windowCheckInterval is how often held items are checked for release
+const windowCheckInterval = time.Minute
+
+// HoldService holds the items arriving outside the delivery window of their
+// exporter in the store, and releases them when the window opens
+type HoldService struct {
+	store     domain.Store
+	queue     *DeliveryQueue
+	runners   *runners
+	mu        sync.RWMutex
+	windows   *window.Set
+	exporters map[string]domain.Exporter
+}
+
+// NewHoldService creates a new hold service
+func NewHoldService(store domain.Store, queue *DeliveryQueue, windows *window.Set, exporters []domain.Exporter) *HoldService {
+	return &HoldService{
+		store:     store,
+		queue:     queue,
+		runners:   newRunners(),
+		windows:   windows,
+		exporters: windowedExporters(windows, exporters),
+	}
+}
+
+// windowedExporters returns the exporters with a delivery window, by ID
+func windowedExporters(windows *window.Set, exporters []domain.Exporter) map[string]domain.Exporter {
+	windowed := make(map[string]domain.Exporter)
+	for _, exporter := range exporters {
+		if windows.Get(exporter.GetID()) != nil {
+			windowed[exporter.GetID()] = exporter
+		}
+	}
+	return windowed
+}
+
+// Start starts releasing the held items of the exporters with a window
+func (s *HoldService) Start(ctx context.Context) {
+	s.runners.setContext(ctx)
+
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	for _, exporter := range s.exporters {
+		s.startRelease(exporter, s.windows.Get(exporter.GetID()))
+	}
+}
+
+// Stop waits for the release schedules to stop. Held items are kept in the
+// store and released after restart.
+func (s *HoldService) Stop() {
+	s.runners.wait()
+}
+
+// Update replaces the delivery windows. Only the release schedules of
+// exporters that were added, removed or replaced by a new instance are
+// restarted; held items are kept in the store.
+func (s *HoldService) Update(windows *window.Set, exporters []domain.Exporter) {
+	updated := windowedExporters(windows, exporters)
+
+	s.mu.Lock()
+	previous := s.exporters
+	s.windows = windows
+	s.exporters = updated
+	s.mu.Unlock()
+
+	for id, exporter := range previous {
+		if updated[id] != exporter {
+			s.runners.stop(id)
+		}
+	}
+	for id, exporter := range updated {
+		if previous[id] != exporter {
+			s.startRelease(exporter, windows.Get(id))
+		}
+	}
+}
+
+// startRelease starts the release schedule of an exporter in its own goroutine
+func (s *HoldService) startRelease(exporter domain.Exporter, w *window.Window) {
+	s.runners.start(exporter.GetID(), func(ctx context.Context) {
+		s.scheduleRelease(ctx, exporter, w)
+	})
+}
+
+// Closed reports whether the delivery window of an exporter is closed
+func (s *HoldService) Closed(exporter domain.Exporter, now time.Time) bool {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return !s.windows.Get(exporter.GetID()).Open(now)
+}
+
+// Hold stores an item, with the TTL of its source, until the delivery window
+// of its exporter opens
+func (s *HoldService) Hold(ctx context.Context, item domain.Item, exporter domain.Exporter, sourceTTL *time.Duration) error {
+	entry := domain.BufferedItem{Item: item, SourceTTL: sourceTTL}
+	count, err := s.store.AppendBuffer(ctx, heldBuffer(exporter.GetID()), entry)
+	if err != nil {
+		return fmt.Errorf("failed to hold item: item=%s exporter=%s error=%w", item.ID, exporter.GetID(), err)
+	}
+
+	logger.Debug("Held item outside delivery window: item=%s exporter=%s held=%d", item.ID, exporter.GetID(), count)
+	return nil
+}
+
+// scheduleRelease releases the held items of an exporter while its window is open
+func (s *HoldService) scheduleRelease(ctx context.Context, exporter domain.Exporter, w *window.Window) {
+	ticker := time.NewTicker(windowCheckInterval)
+	defer ticker.Stop()
+
+	for {
+		if w.Open(time.Now()) {
+			if err := s.release(ctx, exporter, w); err != nil {
+				logger.Error("Failed to release held items: exporter=%s error=%v", exporter.GetID(), err)
+			}
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// release queues the held items of an exporter, as a single digest if the
+// window collapses them
+func (s *HoldService) release(ctx context.Context, exporter domain.Exporter, w *window.Window) error {
+	buffer := heldBuffer(exporter.GetID())
+
+	entries, err := s.store.ReadBuffer(ctx, buffer)
+	if err != nil {
+		return err
+	}
+	if len(entries) == 0 {
+		return nil
+	}
+
+	if _, ok := exporter.(domain.BatchExporter); ok && w.Collapse() && len(entries) > 1 {
+		// The delivery queue removes the items from the buffer once delivered
+		if s.queue.EnqueueBatch(ctx, entries, exporter, buffer) {
+			logger.Info("Released held items as digest: exporter=%s items=%d", exporter.GetID(), len(entries))
+		}
+		return nil
+	}
+
+	// Items stay in the buffer until delivered, so they survive a restart
+	released := 0
+	for _, entry := range entries {
+		if s.queue.EnqueueBuffered(ctx, entry, exporter, buffer) {
+			released++
+		}
+	}
+
+	if released > 0 {
+		logger.Info("Released held items: exporter=%s items=%d", exporter.GetID(), released)
+	}
+	return nil
+}
+
+// heldBuffer returns the name of the store buffer holding the items of an
+// exporter while its window is closed
+func heldBuffer(exporterID string) string {
+	return "held:" + exporterID
+}
@@ -7,71 +7,112 @@ import (
 	"time"
 
 	"github.com/leofvo/bridgr/internal/domain"
+	"github.com/leofvo/bridgr/internal/filters"
+	"github.com/leofvo/bridgr/internal/metrics"
+	"github.com/leofvo/bridgr/internal/routing"
 	"github.com/leofvo/bridgr/pkg/logger"
 )
 
 // NotificationService handles the notification processing
 type NotificationService struct {
-	store domain.Store
+	store   domain.Store
+	queue   *DeliveryQueue
+	digests *DigestService
+	holds   *HoldService
+	mu      sync.RWMutex
+	filters *filters.Set
+	router  *routing.Router
 }
 
 // NewNotificationService creates a new notification service
-func NewNotificationService(store domain.Store) *NotificationService {
+func NewNotificationService(store domain.Store, queue *DeliveryQueue, digests *DigestService, holds *HoldService, filters *filters.Set, router *routing.Router) *NotificationService {
 	return &NotificationService{
-		store: store,
+		store:   store,
+		queue:   queue,
+		digests: digests,
+		holds:   holds,
+		filters: filters,
+		router:  router,
 	}
 }
 
-// ProcessItems processes items and sends notifications
-func (s *NotificationService) ProcessItems(ctx context.Context, items []domain.Item, exporters []domain.Exporter) error {
+// Update replaces the filters and routes applied to new items
+func (s *NotificationService) Update(filters *filters.Set, router *routing.Router) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.filters = filters
+	s.router = router
+}
+
+// rules returns the current filters and routes
+func (s *NotificationService) rules() (*filters.Set, *routing.Router) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.filters, s.router
+}
+
+// ProcessItems queues notifications for the items not yet processed by their
+// exporters. The source TTL, if any, bounds how long they are remembered.
+func (s *NotificationService) ProcessItems(ctx context.Context, items []domain.Item, exporters []domain.Exporter, sourceTTL *time.Duration) error {
 	var wg sync.WaitGroup
 	errChan := make(chan error, len(items)*len(exporters))
+	now := time.Now()
+	itemFilters, router := s.rules()
 
 	for _, item := range items {
-		// Filter exporters by group
-		groupExporters := make([]domain.Exporter, 0)
-		for _, exporter := range exporters {
-			if exporter.GetGroup() == item.Group {
-				groupExporters = append(groupExporters, exporter)
+		for _, exporter := range router.Exporters(item, exporters) {
+			if ok, reason := itemFilters.Allow(item, exporter.GetID(), now); !ok {
+				logger.Debug("Item filtered out: item=%s exporter=%s reason=%s", item.ID, exporter.GetID(), reason)
+				continue
 			}
-		}
 
-		for _, exporter := range groupExporters {
 			wg.Add(1)
 			go func(item domain.Item, exporter domain.Exporter) {
 				defer wg.Done()
 
 				// Check if item has been processed
-				processed, err := s.store.HasProcessed(item.ID, exporter.GetType())
+				processed, err := s.store.HasProcessed(ctx, item.ID, exporter.GetID())
 				if err != nil {
-					errChan <- fmt.Errorf("failed to check if item was processed: item=%s exporter=%s error=%w", item.ID, exporter.GetType(), err)
+					errChan <- fmt.Errorf("failed to check if item was processed: item=%s exporter=%s error=%w", item.ID, exporter.GetID(), err)
 					return
 				}
 
 				if processed {
-					logger.Debug("Item already processed: item=%s exporter=%s", item.ID, exporter.GetType())
+					metrics.DedupHits.WithLabelValues(exporter.GetID()).Inc()
+					logger.Debug("Item already processed: item=%s exporter=%s", item.ID, exporter.GetID())
 					return
 				}
 
-				// Send notification
-				if err := exporter.Export(item); err != nil {
-					errChan <- fmt.Errorf("failed to export item: item=%s exporter=%s error=%w", item.ID, exporter.GetType(), err)
+				// Digest exporters receive the item with the next digest,
+				// sent once their delivery window is open
+				if s.digests.Handles(exporter) {
+					if err := s.digests.Add(ctx, item, exporter, sourceTTL); err != nil {
+						errChan <- err
+					}
 					return
 				}
 
-				// Get source TTL if available
-				var sourceTTL *time.Duration
-				if source, ok := exporter.(interface{ GetSourceTTL() *time.Duration }); ok {
-					sourceTTL = source.GetSourceTTL()
+				// Items arriving outside the delivery window wait for it to open
+				if s.holds.Closed(exporter, now) {
+					if err := s.holds.Hold(ctx, item, exporter, sourceTTL); err != nil {
+						errChan <- err
+					}
+					return
 				}
 
-				// Mark as processed
-				if err := s.store.MarkProcessed(item.ID, exporter.GetType(), sourceTTL); err != nil {
-					errChan <- fmt.Errorf("failed to mark item as processed: item=%s exporter=%s error=%w", item.ID, exporter.GetType(), err)
+				// Queue the notification, the delivery queue marks it as processed once sent
+				enqueued, err := s.queue.Enqueue(ctx, item, exporter, sourceTTL)
+				if err != nil {
+					errChan <- err
 					return
 				}
-
-				logger.Info("Processed item: item=%s exporter=%s", item.ID, exporter.GetType())
+				if !enqueued {
+					if ctx.Err() != nil {
+						errChan <- fmt.Errorf("failed to queue item: item=%s exporter=%s error=%w", item.ID, exporter.GetID(), ctx.Err())
+						return
+					}
+					logger.Debug("Item delivery already pending: item=%s exporter=%s", item.ID, exporter.GetID())
+				}
 			}(item, exporter)
 		}
 	}
@@ -92,4 +133,19 @@ func (s *NotificationService) ProcessItems(ctx context.Context, items []domain.I
 	}
 
 	return nil
-} 
\ No newline at end of file
+}
+
+// SeedItems records items as processed for every exporter of their group
+// without exporting them
+func (s *NotificationService) SeedItems(ctx context.Context, items []domain.Item, exporters []domain.Exporter, sourceTTL *time.Duration) error {
+	_, router := s.rules()
+	for _, item := range items {
+		for _, exporter := range router.Exporters(item, exporters) {
+			if err := s.store.MarkProcessed(ctx, item.ID, exporter.GetID(), sourceTTL); err != nil {
+				return fmt.Errorf("failed to seed item: item=%s exporter=%s error=%w", item.ID, exporter.GetID(), err)
+			}
+		}
+	}
+
+	return nil
+}
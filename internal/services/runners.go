@@ -0,0 +1,82 @@
+package services
+
+import (
+	"context"
+	"sync"
+)
+
+// runner is a goroutine that can be stopped on its own
+type runner struct {
+	cancel context.CancelFunc
+	done   chan struct{}
+}
+
+// runners manages goroutines keyed by ID, so that they can be started and
+// stopped individually when the configuration is reloaded
+type runners struct {
+	mu      sync.Mutex
+	ctx     context.Context
+	running map[string]*runner
+}
+
+// newRunners creates an empty set of runners
+func newRunners() *runners {
+	return &runners{
+		running: make(map[string]*runner),
+	}
+}
+
+// setContext sets the parent context of the goroutines started afterwards
+func (r *runners) setContext(ctx context.Context) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.ctx = ctx
+}
+
+// start runs fn in a goroutine under the given ID, stopping any goroutine
+// already running under it
+func (r *runners) start(id string, fn func(ctx context.Context)) {
+	r.stop(id)
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	ctx, cancel := context.WithCancel(r.ctx)
+	run := &runner{
+		cancel: cancel,
+		done:   make(chan struct{}),
+	}
+	r.running[id] = run
+
+	go func() {
+		defer close(run.done)
+		fn(ctx)
+	}()
+}
+
+// stop cancels the goroutine running under an ID and waits for it to return
+func (r *runners) stop(id string) {
+	r.mu.Lock()
+	run, ok := r.running[id]
+	delete(r.running, id)
+	r.mu.Unlock()
+
+	if ok {
+		run.cancel()
+		<-run.done
+	}
+}
+
+// wait waits for every goroutine to return, once their context is cancelled
+func (r *runners) wait() {
+	r.mu.Lock()
+	running := make([]*runner, 0, len(r.running))
+	for _, run := range r.running {
+		running = append(running, run)
+	}
+	r.mu.Unlock()
+
+	for _, run := range running {
+		<-run.done
+	}
+}
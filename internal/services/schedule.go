@@ -0,0 +1,49 @@
+package services
+
+import (
+	"fmt"
+	"math/rand"
+	"time"
+
+	"github.com/robfig/cron/v3"
+
+	"github.com/leofvo/bridgr/internal/domain"
+)
+
+// pollSchedule returns the time of the next poll after the given time
+type pollSchedule interface {
+	Next(t time.Time) time.Time
+}
+
+// intervalSchedule polls at a fixed interval
+type intervalSchedule time.Duration
+
+// Next implements pollSchedule
+func (s intervalSchedule) Next(t time.Time) time.Time {
+	return t.Add(time.Duration(s))
+}
+
+// sourceSchedule returns the polling schedule of a source: its cron schedule
+// if configured, otherwise its interval. Interval sources are polled as soon
+// as they are scheduled, cron sources on their first occurrence.
+func sourceSchedule(source domain.Source) (pollSchedule, bool, error) {
+	if src, ok := source.(interface{ GetSchedule() string }); ok && src.GetSchedule() != "" {
+		schedule, err := cron.ParseStandard(src.GetSchedule())
+		if err != nil {
+			return nil, false, fmt.Errorf("invalid schedule: %w", err)
+		}
+		return schedule, false, nil
+	}
+
+	return intervalSchedule(source.GetInterval()), true, nil
+}
+
+// sourceJitter returns a random delay up to the jitter of a source, so that
+// sources sharing a schedule do not poll in lockstep
+func sourceJitter(source domain.Source) time.Duration {
+	src, ok := source.(interface{ GetJitter() time.Duration })
+	if !ok || src.GetJitter() <= 0 {
+		return 0
+	}
+	return time.Duration(rand.Int63n(int64(src.GetJitter())))
+}
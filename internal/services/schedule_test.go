@@ -0,0 +1,97 @@
+package services
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/leofvo/bridgr/internal/domain"
+)
+
+// testSource is a source only providing its schedule settings
+type testSource struct {
+	interval time.Duration
+	schedule string
+	jitter   time.Duration
+}
+
+func (s testSource) Fetch(ctx context.Context) (*domain.FetchResult, error) {
+	return &domain.FetchResult{}, nil
+}
+func (s testSource) GetID() string              { return "test" }
+func (s testSource) GetType() string            { return "rss" }
+func (s testSource) GetInterval() time.Duration { return s.interval }
+func (s testSource) GetGroup() string           { return "news" }
+func (s testSource) GetSchedule() string        { return s.schedule }
+func (s testSource) GetJitter() time.Duration   { return s.jitter }
+
+func TestSourceSchedule(t *testing.T) {
+	// 2024-05-06 is a Monday
+	now := time.Date(2024, 5, 6, 10, 17, 0, 0, time.UTC)
+
+	tests := []struct {
+		name      string
+		source    testSource
+		immediate bool
+		next      time.Time
+		wantErr   bool
+	}{
+		{
+			name:      "interval",
+			source:    testSource{interval: 5 * time.Minute},
+			immediate: true,
+			next:      now.Add(5 * time.Minute),
+		},
+		{
+			name:   "cron expression",
+			source: testSource{interval: 5 * time.Minute, schedule: "*/15 * * * *"},
+			next:   time.Date(2024, 5, 6, 10, 30, 0, 0, time.UTC),
+		},
+		{
+			name:   "cron descriptor",
+			source: testSource{schedule: "@daily"},
+			next:   time.Date(2024, 5, 7, 0, 0, 0, 0, time.UTC),
+		},
+		{
+			name:   "cron time zone",
+			source: testSource{schedule: "CRON_TZ=Europe/Paris 0 9 * * 1-5"},
+			next:   time.Date(2024, 5, 7, 7, 0, 0, 0, time.UTC),
+		},
+		{
+			name:    "invalid cron expression",
+			source:  testSource{schedule: "every monday"},
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			schedule, immediate, err := sourceSchedule(tt.source)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("sourceSchedule() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if tt.wantErr {
+				return
+			}
+			if immediate != tt.immediate {
+				t.Errorf("sourceSchedule() immediate = %v, want %v", immediate, tt.immediate)
+			}
+			if next := schedule.Next(now); !next.Equal(tt.next) {
+				t.Errorf("Next() = %v, want %v", next, tt.next)
+			}
+		})
+	}
+}
+
+func TestSourceJitter(t *testing.T) {
+	if jitter := sourceJitter(testSource{}); jitter != 0 {
+		t.Errorf("sourceJitter() = %v without jitter, want 0", jitter)
+	}
+
+	source := testSource{jitter: time.Second}
+	for i := 0; i < 100; i++ {
+		if jitter := sourceJitter(source); jitter < 0 || jitter >= time.Second {
+			t.Fatalf("sourceJitter() = %v, want within [0, 1s)", jitter)
+		}
+	}
+}
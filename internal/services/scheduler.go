@@ -3,75 +3,315 @@ package services
 import (
 	"context"
 	"fmt"
+	"math"
 	"sync"
 	"time"
 
+	"github.com/leofvo/bridgr/internal/config"
 	"github.com/leofvo/bridgr/internal/domain"
+	"github.com/leofvo/bridgr/internal/metrics"
 	"github.com/leofvo/bridgr/pkg/logger"
 )
 
 // SchedulerService manages the scheduling of source polling
 type SchedulerService struct {
 	notificationService *NotificationService
-	sources            []domain.Source
+	store              domain.Store
+	queue              *DeliveryQueue
+	health             *HealthTracker
+	runners            *runners
+	mu                 sync.RWMutex
+	sources            map[string]domain.Source
 	exporters          []domain.Exporter
-	wg                 sync.WaitGroup
+	polling            *config.PollingConfig
+	alerter            domain.Exporter
 }
 
 // NewSchedulerService creates a new scheduler service
-func NewSchedulerService(notificationService *NotificationService, sources []domain.Source, exporters []domain.Exporter) *SchedulerService {
-	return &SchedulerService{
+func NewSchedulerService(notificationService *NotificationService, store domain.Store, queue *DeliveryQueue, sources []domain.Source, exporters []domain.Exporter, polling *config.PollingConfig) *SchedulerService {
+	s := &SchedulerService{
 		notificationService: notificationService,
-		sources:            sources,
-		exporters:          exporters,
+		store:              store,
+		queue:              queue,
+		health:             NewHealthTracker(),
+		runners:            newRunners(),
+		sources:            make(map[string]domain.Source, len(sources)),
 	}
+
+	for _, source := range sources {
+		s.sources[source.GetID()] = source
+	}
+	s.setExporters(exporters, polling)
+
+	return s
 }
 
 // Start starts the scheduler
 func (s *SchedulerService) Start(ctx context.Context) error {
+	s.runners.setContext(ctx)
+
+	s.mu.RLock()
+	defer s.mu.RUnlock()
 	for _, source := range s.sources {
-		s.wg.Add(1)
-		go func(source domain.Source) {
-			defer s.wg.Done()
-			s.scheduleSource(ctx, source)
-		}(source)
+		s.startSource(source)
 	}
 
 	return nil
 }
 
-// Stop stops the scheduler
+// Stop waits for the sources to stop polling once the context is cancelled
 func (s *SchedulerService) Stop() {
-	s.wg.Wait()
+	s.runners.wait()
+}
+
+// Update replaces the sources, exporters and polling settings. Only the
+// sources that were added, removed or replaced by a new instance are
+// restarted, the others keep their schedule.
+func (s *SchedulerService) Update(sources []domain.Source, exporters []domain.Exporter, polling *config.PollingConfig) {
+	s.setExporters(exporters, polling)
+
+	updated := make(map[string]domain.Source, len(sources))
+	for _, source := range sources {
+		updated[source.GetID()] = source
+	}
+
+	s.mu.Lock()
+	previous := s.sources
+	s.sources = updated
+	s.mu.Unlock()
+
+	for id, source := range previous {
+		if updated[id] != source {
+			s.runners.stop(id)
+			if _, exists := updated[id]; !exists {
+				s.health.Remove(id)
+				logger.Info("Stopped polling removed source: source=%s", id)
+			}
+		}
+	}
+
+	for id, source := range updated {
+		if previous[id] != source {
+			s.startSource(source)
+			logger.Info("Started polling source: source=%s", id)
+		}
+	}
+}
+
+// startSource starts polling a source in its own goroutine
+func (s *SchedulerService) startSource(source domain.Source) {
+	s.runners.start(source.GetID(), func(ctx context.Context) {
+		s.scheduleSource(ctx, source)
+	})
+}
+
+// setExporters replaces the exporters and polling settings
+func (s *SchedulerService) setExporters(exporters []domain.Exporter, polling *config.PollingConfig) {
+	var alerter domain.Exporter
+	if polling.Alert != nil {
+		for _, exporter := range exporters {
+			if exporter.GetID() == polling.Alert.Exporter {
+				alerter = exporter
+			}
+		}
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.exporters = exporters
+	s.polling = polling
+	s.alerter = alerter
 }
 
-// scheduleSource schedules a source for polling
+// settings returns the current exporters and polling settings
+func (s *SchedulerService) settings() ([]domain.Exporter, *config.PollingConfig, domain.Exporter) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.exporters, s.polling, s.alerter
+}
+
+// scheduleSource polls a source on its schedule until the context is
+// cancelled, backing off after consecutive failures
 func (s *SchedulerService) scheduleSource(ctx context.Context, source domain.Source) {
-	ticker := time.NewTicker(source.GetInterval())
-	defer ticker.Stop()
+	schedule, immediate, err := sourceSchedule(source)
+	if err != nil {
+		logger.Error("Failed to schedule source: source=%s error=%v", source.GetID(), err)
+		return
+	}
+
+	var wait time.Duration
+	if !immediate {
+		wait = time.Until(schedule.Next(time.Now()))
+	}
 
 	for {
+		wait += sourceJitter(source)
+		s.health.SetNextPoll(source.GetID(), time.Now().Add(wait))
+
+		timer := time.NewTimer(wait)
 		select {
 		case <-ctx.Done():
+			timer.Stop()
 			return
-		case <-ticker.C:
-			if err := s.pollSource(ctx, source); err != nil {
-				logger.Error("Failed to poll source: source=%s error=%v", source.GetType(), err)
-			}
+		case <-timer.C:
 		}
+
+		err := s.pollSource(ctx, source)
+		if ctx.Err() != nil {
+			return
+		}
+		now := time.Now()
+		if err != nil {
+			health := s.health.RecordFailure(source.GetID(), err, now)
+			logger.Error("Failed to poll source: source=%s failures=%d error=%v", source.GetID(), health.ConsecutiveFailures, err)
+			s.alertFailing(ctx, source, health, now)
+		} else if previous := s.health.RecordSuccess(source.GetID(), now); previous.ConsecutiveFailures > 0 {
+			logger.Info("Source recovered: source=%s failures=%d", source.GetID(), previous.ConsecutiveFailures)
+			s.alertRecovered(ctx, source, previous, now)
+		}
+
+		wait = s.pollDelay(source, schedule, immediate, now)
+	}
+}
+
+// pollDelay computes the delay before the next poll of a source: its
+// schedule, or its observed publish frequency for adaptive sources, slowed
+// down exponentially after consecutive failures
+func (s *SchedulerService) pollDelay(source domain.Source, schedule pollSchedule, interval bool, now time.Time) time.Duration {
+	delay := schedule.Next(now).Sub(now)
+	if interval {
+		if adaptive, ok := s.adaptiveInterval(source, now); ok {
+			delay = adaptive
+		}
+	}
+
+	failures := s.health.Get(source.GetID()).ConsecutiveFailures
+	if failures == 0 {
+		return delay
+	}
+
+	_, polling, _ := s.settings()
+	backoff := float64(delay) * math.Pow(polling.Backoff.Multiplier, float64(failures))
+	backoff = math.Min(backoff, float64(polling.Backoff.MaxInterval))
+	if time.Duration(backoff) <= delay {
+		return delay
 	}
+	if !interval {
+		// Cron sources resume on the first occurrence after the backoff
+		return schedule.Next(now.Add(time.Duration(backoff))).Sub(now)
+	}
+	return time.Duration(backoff)
+}
+
+// adaptiveInterval returns the polling interval of an adaptive source, derived
+// from the publish frequency of its items and bounded by its configuration
+func (s *SchedulerService) adaptiveInterval(source domain.Source, now time.Time) (time.Duration, bool) {
+	src, ok := source.(interface{ GetAdaptive() *config.AdaptiveConfig })
+	if !ok || src.GetAdaptive() == nil {
+		return 0, false
+	}
+	adaptive := src.GetAdaptive()
+
+	publishInterval, ok := s.health.PublishInterval(source.GetID(), now)
+	if !ok {
+		return 0, false
+	}
+
+	interval := publishInterval / adaptivePollsPerItem
+	if interval < adaptive.MinInterval {
+		interval = adaptive.MinInterval
+	}
+	if interval > adaptive.MaxInterval {
+		interval = adaptive.MaxInterval
+	}
+	return interval, true
+}
+
+// Health returns the polling health of every source
+func (s *SchedulerService) Health() []SourceHealth {
+	return s.health.Snapshot()
 }
 
 // pollSource polls a source for new items
 func (s *SchedulerService) pollSource(ctx context.Context, source domain.Source) error {
-	items, err := source.Fetch()
+	// A source without state has never been polled successfully
+	state, err := s.store.GetSourceState(ctx, source.GetID())
+	if err != nil {
+		return fmt.Errorf("failed to load source state: %w", err)
+	}
+	firstRun := state == nil
+
+	start := time.Now()
+	result, err := source.Fetch(ctx)
 	if err != nil {
+		metrics.ObserveFetch(source.GetID(), start, 0, err)
 		return fmt.Errorf("failed to fetch items: %w", err)
 	}
+	metrics.ObserveFetch(source.GetID(), start, len(result.Items), nil)
+	s.health.RecordPublished(source.GetID(), result.Items)
+
+	items := result.Items
+	if firstRun {
+		items, err = s.backfill(ctx, source, items)
+		if err != nil {
+			return err
+		}
+	}
+
+	if len(items) > 0 {
+		exporters, _, _ := s.settings()
+		if err := s.notificationService.ProcessItems(ctx, items, exporters, sourceTTL(source)); err != nil {
+			return err
+		}
+	}
+
+	return s.commitFetch(ctx, source, result)
+}
 
-	if len(items) == 0 {
-		return nil
+// commitFetch saves the source state and validators of a fetch once its
+// items are durably queued, so that items are fetched again after a failed poll
+func (s *SchedulerService) commitFetch(ctx context.Context, source domain.Source, result *domain.FetchResult) error {
+	if result.State != nil {
+		if err := s.store.SaveSourceState(ctx, source.GetID(), result.State); err != nil {
+			return fmt.Errorf("failed to save source state: %w", err)
+		}
 	}
 
-	return s.notificationService.ProcessItems(ctx, items, s.exporters)
-} 
\ No newline at end of file
+	// Validators are saved last, a missing cache only costs a full fetch
+	if result.Cache != nil {
+		if err := s.store.SetFetchCache(ctx, source.GetID(), result.Cache); err != nil {
+			logger.Warn("Failed to save fetch cache: source=%s error=%v", source.GetID(), err)
+		}
+	}
+	return nil
+}
+
+// backfill applies the source backfill setting to the items of its first poll,
+// recording older items as processed without exporting them
+func (s *SchedulerService) backfill(ctx context.Context, source domain.Source, items []domain.Item) ([]domain.Item, error) {
+	var backfill *config.BackfillConfig
+	if src, ok := source.(interface{ GetBackfill() *config.BackfillConfig }); ok {
+		backfill = src.GetBackfill()
+	}
+
+	export, seed := splitBackfill(items, backfill, time.Now())
+	if len(seed) == 0 {
+		return export, nil
+	}
+
+	exporters, _, _ := s.settings()
+	if err := s.notificationService.SeedItems(ctx, seed, exporters, sourceTTL(source)); err != nil {
+		return nil, fmt.Errorf("failed to seed backfilled items: %w", err)
+	}
+
+	logger.Info("Backfilled new source: source=%s exported=%d seeded=%d", source.GetID(), len(export), len(seed))
+	return export, nil
+}
+
+// sourceTTL returns the TTL of the processed markers of a source's items, if configured
+func sourceTTL(source domain.Source) *time.Duration {
+	if src, ok := source.(interface{ GetSourceTTL() *time.Duration }); ok {
+		return src.GetSourceTTL()
+	}
+	return nil
+}
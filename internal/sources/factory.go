@@ -8,18 +8,22 @@ import (
 )
 
 // Factory creates new source instances
-type Factory struct{}
+type Factory struct {
+	store domain.Store
+}
 
 // NewFactory creates a new source factory
-func NewFactory() *Factory {
-	return &Factory{}
+func NewFactory(store domain.Store) *Factory {
+	return &Factory{
+		store: store,
+	}
 }
 
 // CreateSource creates a new source based on the configuration
 func (f *Factory) CreateSource(cfg *config.SourceConfig, group string) (domain.Source, error) {
 	switch cfg.Type {
-	case "rss":
-		return NewRSSSource(cfg, group), nil
+	case config.SourceTypeRSS:
+		return NewRSSSource(cfg, group, f.store), nil
 	default:
 		return nil, fmt.Errorf("unknown source type: %s", cfg.Type)
 	}
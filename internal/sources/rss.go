@@ -1,7 +1,9 @@
 package sources
 
 import (
+	"context"
 	"fmt"
+	"net/http"
 	"time"
 
 	"github.com/leofvo/bridgr/internal/config"
@@ -11,35 +13,133 @@ import (
 	"github.com/mmcdole/gofeed"
 )
 
+const (
+	// userAgent is sent with every feed request
+	userAgent = "Bridgr/1.0"
+
+	// maxSeenIDs bounds the number of item IDs remembered per source
+	maxSeenIDs = 500
+)
+
+// Reasons for which fetched items are skipped
+const (
+	SkipSeen            = "already seen"
+	SkipOlderThanCursor = "older than cursor"
+	SkipEmptyGUID       = "empty GUID"
+	SkipEmptyTitle      = "empty title"
+)
+
 // RSSSource implements the Source interface for RSS feeds
 type RSSSource struct {
-	config  *config.SourceConfig
-	parser  *gofeed.Parser
-	group   string
-	lastRun time.Time
+	id     string
+	config *config.SourceConfig
+	parser *gofeed.Parser
+	client *http.Client
+	store  domain.Store
+	group  string
 }
 
 // NewRSSSource creates a new RSS source
-func NewRSSSource(cfg *config.SourceConfig, group string) *RSSSource {
+func NewRSSSource(cfg *config.SourceConfig, group string, store domain.Store) *RSSSource {
 	return &RSSSource{
+		id:     cfg.ResolveID(group),
 		config: cfg,
 		parser: gofeed.NewParser(),
-		group:  group,
+		client: &http.Client{
+			Timeout: 30 * time.Second,
+		},
+		store: store,
+		group: group,
 	}
 }
 
-// Fetch retrieves items from the RSS feed
-func (s *RSSSource) Fetch() ([]domain.Item, error) {
-	feed, err := s.parser.ParseURL(s.config.URL)
+// Fetch retrieves items from the RSS feed that were not returned by a
+// previous poll, resuming from the persisted source state. The new state and
+// validators are returned rather than saved, so that they are only committed
+// once the items are processed.
+func (s *RSSSource) Fetch(ctx context.Context) (*domain.FetchResult, error) {
+	state, err := s.store.GetSourceState(ctx, s.id)
 	if err != nil {
-		return nil, fmt.Errorf("failed to parse RSS feed: url=%s error=%w", s.config.URL, err)
+		return nil, fmt.Errorf("failed to load source state: url=%s error=%w", s.config.URL, err)
+	}
+
+	// Without a state every item must be evaluated, so skip conditional requests
+	feed, cache, err := s.fetchFeed(ctx, state != nil)
+	if err != nil {
+		return nil, err
+	}
+
+	if state == nil {
+		state = &domain.SourceState{}
 	}
 
+	if feed == nil {
+		logger.Debug("RSS feed not modified: url=%s", s.config.URL)
+		state.LastSuccess = time.Now()
+		return &domain.FetchResult{State: state}, nil
+	}
+
+	items, skipped, cursor, seenIDs := s.parseItems(feed, state)
+	for _, skippedItem := range skipped {
+		switch skippedItem.Reason {
+		case SkipEmptyGUID:
+			logger.Warn("Skipping item with empty GUID: url=%s title=%s", s.config.URL, skippedItem.Item.Title)
+		case SkipEmptyTitle:
+			logger.Warn("Skipping item with empty title: url=%s guid=%s", s.config.URL, skippedItem.Item.ID)
+		}
+	}
+
+	logger.Info("Fetched RSS feed: url=%s items=%d", s.config.URL, len(items))
+	return &domain.FetchResult{
+		Items: items,
+		State: &domain.SourceState{
+			Cursor:      cursor,
+			SeenIDs:     seenIDs,
+			LastSuccess: time.Now(),
+		},
+		Cache: cache,
+	}, nil
+}
+
+// Preview fetches the feed once and returns the items a poll would return,
+// and the skipped items with the reason, without saving any state. The
+// stored source state is only taken into account when the source has a store.
+func (s *RSSSource) Preview(ctx context.Context) ([]domain.Item, []domain.SkippedItem, error) {
+	state := &domain.SourceState{}
+	if s.store != nil {
+		stored, err := s.store.GetSourceState(ctx, s.id)
+		if err != nil {
+			return nil, nil, fmt.Errorf("failed to load source state: url=%s error=%w", s.config.URL, err)
+		}
+		if stored != nil {
+			state = stored
+		}
+	}
+
+	feed, _, err := s.fetchFeed(ctx, false)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	items, skipped, _, _ := s.parseItems(feed, state)
+	return items, skipped, nil
+}
+
+// parseItems converts the items of a feed, skipping the items already
+// returned by a previous poll, older than the cursor or missing required
+// fields. It also returns the new cursor and the IDs to remember.
+func (s *RSSSource) parseItems(feed *gofeed.Feed, state *domain.SourceState) ([]domain.Item, []domain.SkippedItem, time.Time, []string) {
 	items := make([]domain.Item, 0, len(feed.Items))
+	var skipped []domain.SkippedItem
+	cursor := state.Cursor
+	seenIDs := make([]string, 0, len(feed.Items))
+
 	for _, item := range feed.Items {
-		// Skip items older than last run
-		if item.PublishedParsed != nil && s.lastRun.After(*item.PublishedParsed) {
-			continue
+		if item.GUID != "" && len(seenIDs) < maxSeenIDs {
+			seenIDs = append(seenIDs, item.GUID)
+		}
+		if item.PublishedParsed != nil && item.PublishedParsed.After(cursor) {
+			cursor = *item.PublishedParsed
 		}
 
 		publishedAt := time.Now()
@@ -47,35 +147,100 @@ func (s *RSSSource) Fetch() ([]domain.Item, error) {
 			publishedAt = *item.PublishedParsed
 		}
 
+		// Clean HTML content
+		normalized := domain.Item{
+			ID:          item.GUID,
+			Title:       utils.StripHTML(item.Title),
+			Description: utils.StripHTML(item.Description),
+			Link:        item.Link,
+			PublishedAt: publishedAt,
+			Categories:  item.Categories,
+			Source:      s.config.URL,
+			SourceID:    s.id,
+			Group:       s.group,
+		}
+
+		var reason string
+		switch {
+		// Skip items already returned by a previous poll
+		case state.HasSeen(item.GUID):
+			reason = SkipSeen
+		// Skip items older than the cursor
+		case item.PublishedParsed != nil && state.Cursor.After(*item.PublishedParsed):
+			reason = SkipOlderThanCursor
 		// Validate required fields
-		if item.GUID == "" {
-			logger.Warn("Skipping item with empty GUID: url=%s title=%s", s.config.URL, item.Title)
-			continue
+		case item.GUID == "":
+			reason = SkipEmptyGUID
+		case item.Title == "":
+			reason = SkipEmptyTitle
 		}
 
-		if item.Title == "" {
-			logger.Warn("Skipping item with empty title: url=%s guid=%s", s.config.URL, item.GUID)
+		if reason != "" {
+			skipped = append(skipped, domain.SkippedItem{Item: normalized, Reason: reason})
 			continue
 		}
+		items = append(items, normalized)
+	}
 
-		// Clean HTML content
-		title := utils.StripHTML(item.Title)
-		description := utils.StripHTML(item.Description)
+	return items, skipped, cursor, seenIDs
+}
 
-		items = append(items, domain.Item{
-			ID:          item.GUID,
-			Title:       title,
-			Description: description,
-			Link:        item.Link,
-			PublishedAt: publishedAt,
-			Source:      s.config.URL,
-			Group:       s.group,
-		})
+// fetchFeed downloads and parses the feed, using the stored ETag and
+// Last-Modified validators to skip unchanged feeds. It returns a nil feed
+// when the server answers 304 Not Modified, and the validators of the
+// response to store once the items are processed.
+func (s *RSSSource) fetchFeed(ctx context.Context, conditional bool) (*gofeed.Feed, *domain.FetchCache, error) {
+	req, err := http.NewRequestWithContext(ctx, "GET", s.config.URL, nil)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to create request: url=%s error=%w", s.config.URL, err)
 	}
+	req.Header.Set("User-Agent", userAgent)
 
-	s.lastRun = time.Now()
-	logger.Info("Fetched RSS feed: url=%s items=%d", s.config.URL, len(items))
-	return items, nil
+	var cache *domain.FetchCache
+	if conditional {
+		cache, err = s.store.GetFetchCache(ctx, s.id)
+		if err != nil {
+			logger.Warn("Failed to load fetch cache, fetching unconditionally: url=%s error=%v", s.config.URL, err)
+		}
+	}
+	if cache != nil {
+		if cache.ETag != "" {
+			req.Header.Set("If-None-Match", cache.ETag)
+		}
+		if cache.LastModified != "" {
+			req.Header.Set("If-Modified-Since", cache.LastModified)
+		}
+	}
+
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to fetch RSS feed: url=%s error=%w", s.config.URL, err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode == http.StatusNotModified {
+		return nil, nil, nil
+	}
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return nil, nil, fmt.Errorf("failed to fetch RSS feed: url=%s status=%d", s.config.URL, resp.StatusCode)
+	}
+
+	feed, err := s.parser.Parse(resp.Body)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to parse RSS feed: url=%s error=%w", s.config.URL, err)
+	}
+
+	newCache := &domain.FetchCache{
+		ETag:         resp.Header.Get("ETag"),
+		LastModified: resp.Header.Get("Last-Modified"),
+	}
+	return feed, newCache, nil
+}
+
+// GetID returns the source identifier
+func (s *RSSSource) GetID() string {
+	return s.id
 }
 
 // GetType returns the source type
@@ -93,6 +258,31 @@ func (s *RSSSource) GetGroup() string {
 	return s.group
 }
 
+// GetURL returns the feed URL
+func (s *RSSSource) GetURL() string {
+	return s.config.URL
+}
+
+// GetAdaptive returns the adaptive polling bounds, if configured
+func (s *RSSSource) GetAdaptive() *config.AdaptiveConfig {
+	return s.config.Adaptive
+}
+
+// GetSchedule returns the cron expression of the polling schedule, if any
+func (s *RSSSource) GetSchedule() string {
+	return s.config.Schedule
+}
+
+// GetJitter returns the maximum random delay added to each poll
+func (s *RSSSource) GetJitter() time.Duration {
+	return s.config.Jitter
+}
+
+// GetBackfill returns the backfill configuration applied on the first poll
+func (s *RSSSource) GetBackfill() *config.BackfillConfig {
+	return s.config.Backfill
+}
+
 // GetSourceTTL returns the source-specific TTL if configured
 func (s *RSSSource) GetSourceTTL() *time.Duration {
 	if s.config.TTL > 0 {
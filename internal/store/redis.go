@@ -2,14 +2,29 @@ package store
 
 import (
 	"context"
+	"encoding/json"
 	"fmt"
+	"sort"
+	"strings"
 	"time"
 
 	"github.com/go-redis/redis/v8"
 	"github.com/leofvo/bridgr/internal/config"
+	"github.com/leofvo/bridgr/internal/domain"
+	"github.com/leofvo/bridgr/internal/metrics"
 	"github.com/leofvo/bridgr/pkg/logger"
 )
 
+const (
+	processedKeyPrefix = "bridgr:processed"
+	sourceKeyPrefix    = "bridgr:source"
+	deadLetterKey      = "bridgr:deadletter"
+	bufferKeyPrefix    = "bridgr:buffer"
+
+	// exporterIDMigrationKey marks the legacy processed keys as migrated
+	exporterIDMigrationKey = "bridgr:migrations:exporter_ids"
+)
+
 // RedisStore implements the Store interface using Redis
 type RedisStore struct {
 	client *redis.Client
@@ -39,9 +54,10 @@ func NewRedisStore(cfg *config.RedisConfig) (*RedisStore, error) {
 }
 
 // HasProcessed checks if an item has been processed by an exporter
-func (s *RedisStore) HasProcessed(itemID, exporterID string) (bool, error) {
-	ctx := context.Background()
-	key := fmt.Sprintf("bridgr:processed:%s:%s", exporterID, itemID)
+func (s *RedisStore) HasProcessed(ctx context.Context, itemID, exporterID string) (bool, error) {
+	defer metrics.ObserveStore("has_processed", time.Now())
+
+	key := processedKey(exporterID, itemID)
 
 	exists, err := s.client.Exists(ctx, key).Result()
 	if err != nil {
@@ -52,9 +68,10 @@ func (s *RedisStore) HasProcessed(itemID, exporterID string) (bool, error) {
 }
 
 // MarkProcessed marks an item as processed by an exporter
-func (s *RedisStore) MarkProcessed(itemID, exporterID string, sourceTTL *time.Duration) error {
-	ctx := context.Background()
-	key := fmt.Sprintf("bridgr:processed:%s:%s", exporterID, itemID)
+func (s *RedisStore) MarkProcessed(ctx context.Context, itemID, exporterID string, sourceTTL *time.Duration) error {
+	defer metrics.ObserveStore("mark_processed", time.Now())
+
+	key := processedKey(exporterID, itemID)
 
 	// Use source-specific TTL if provided, otherwise use global TTL
 	ttl := s.config.TTL
@@ -70,15 +87,316 @@ func (s *RedisStore) MarkProcessed(itemID, exporterID string, sourceTTL *time.Du
 	return nil
 }
 
+// GetFetchCache returns the HTTP validators stored for a source, or nil if none
+func (s *RedisStore) GetFetchCache(ctx context.Context, sourceID string) (*domain.FetchCache, error) {
+	defer metrics.ObserveStore("get_fetch_cache", time.Now())
+
+	key := sourceKey(sourceID, "http")
+
+	values, err := s.client.HGetAll(ctx, key).Result()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get fetch cache: source=%s error=%w", sourceID, err)
+	}
+	if len(values) == 0 {
+		return nil, nil
+	}
+
+	return &domain.FetchCache{
+		ETag:         values["etag"],
+		LastModified: values["last_modified"],
+	}, nil
+}
+
+// SetFetchCache stores the HTTP validators of the last successful source fetch
+func (s *RedisStore) SetFetchCache(ctx context.Context, sourceID string, cache *domain.FetchCache) error {
+	defer metrics.ObserveStore("set_fetch_cache", time.Now())
+
+	key := sourceKey(sourceID, "http")
+
+	pipe := s.client.TxPipeline()
+	pipe.Del(ctx, key)
+	if cache.ETag != "" || cache.LastModified != "" {
+		pipe.HSet(ctx, key, "etag", cache.ETag, "last_modified", cache.LastModified)
+		pipe.Expire(ctx, key, s.config.TTL)
+	}
+	if _, err := pipe.Exec(ctx); err != nil {
+		return fmt.Errorf("failed to set fetch cache: source=%s error=%w", sourceID, err)
+	}
+
+	return nil
+}
+
+// GetSourceState returns the persisted state of a source, or nil if the source
+// has never been polled successfully
+func (s *RedisStore) GetSourceState(ctx context.Context, sourceID string) (*domain.SourceState, error) {
+	defer metrics.ObserveStore("get_source_state", time.Now())
+
+	key := sourceKey(sourceID, "state")
+
+	data, err := s.client.Get(ctx, key).Bytes()
+	if err == redis.Nil {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get source state: source=%s error=%w", sourceID, err)
+	}
+
+	var state domain.SourceState
+	if err := json.Unmarshal(data, &state); err != nil {
+		return nil, fmt.Errorf("failed to decode source state: source=%s error=%w", sourceID, err)
+	}
+
+	return &state, nil
+}
+
+// SaveSourceState persists the state of a source
+func (s *RedisStore) SaveSourceState(ctx context.Context, sourceID string, state *domain.SourceState) error {
+	defer metrics.ObserveStore("save_source_state", time.Now())
+
+	key := sourceKey(sourceID, "state")
+
+	data, err := json.Marshal(state)
+	if err != nil {
+		return fmt.Errorf("failed to encode source state: source=%s error=%w", sourceID, err)
+	}
+
+	// Source state never expires, otherwise old items would be considered new again
+	if err := s.client.Set(ctx, key, data, 0).Err(); err != nil {
+		return fmt.Errorf("failed to save source state: source=%s error=%w", sourceID, err)
+	}
+
+	return nil
+}
+
+// AddDeadLetter records a failed delivery, replacing any previous entry with the same ID
+func (s *RedisStore) AddDeadLetter(ctx context.Context, entry *domain.DeadLetter) error {
+	defer metrics.ObserveStore("add_dead_letter", time.Now())
+
+	data, err := json.Marshal(entry)
+	if err != nil {
+		return fmt.Errorf("failed to encode dead letter: id=%s error=%w", entry.ID, err)
+	}
+
+	if err := s.client.HSet(ctx, deadLetterKey, entry.ID, data).Err(); err != nil {
+		return fmt.Errorf("failed to add dead letter: id=%s error=%w", entry.ID, err)
+	}
+
+	return nil
+}
+
+// ListDeadLetters returns all dead letters, oldest first
+func (s *RedisStore) ListDeadLetters(ctx context.Context) ([]domain.DeadLetter, error) {
+	defer metrics.ObserveStore("list_dead_letters", time.Now())
+
+	values, err := s.client.HGetAll(ctx, deadLetterKey).Result()
+	if err != nil {
+		return nil, fmt.Errorf("failed to list dead letters: %w", err)
+	}
+
+	entries := make([]domain.DeadLetter, 0, len(values))
+	for id, data := range values {
+		var entry domain.DeadLetter
+		if err := json.Unmarshal([]byte(data), &entry); err != nil {
+			logger.Error("Failed to decode dead letter: id=%s error=%v", id, err)
+			continue
+		}
+		entries = append(entries, entry)
+	}
+
+	sort.Slice(entries, func(i, j int) bool {
+		return entries[i].FailedAt.Before(entries[j].FailedAt)
+	})
+
+	return entries, nil
+}
+
+// RemoveDeadLetter deletes a dead letter
+func (s *RedisStore) RemoveDeadLetter(ctx context.Context, id string) error {
+	defer metrics.ObserveStore("remove_dead_letter", time.Now())
+
+	if err := s.client.HDel(ctx, deadLetterKey, id).Err(); err != nil {
+		return fmt.Errorf("failed to remove dead letter: id=%s error=%w", id, err)
+	}
+
+	return nil
+}
+
+// AppendBuffer adds an item to a named buffer, replacing any buffered item
+// with the same ID, and returns the number of buffered items
+func (s *RedisStore) AppendBuffer(ctx context.Context, name string, entry domain.BufferedItem) (int, error) {
+	defer metrics.ObserveStore("append_buffer", time.Now())
+
+	key := bufferKey(name)
+
+	data, err := json.Marshal(entry)
+	if err != nil {
+		return 0, fmt.Errorf("failed to encode buffered item: buffer=%s item=%s error=%w", name, entry.Item.ID, err)
+	}
+
+	pipe := s.client.TxPipeline()
+	pipe.HSet(ctx, key, entry.Item.ID, data)
+	length := pipe.HLen(ctx, key)
+	if _, err := pipe.Exec(ctx); err != nil {
+		return 0, fmt.Errorf("failed to append to buffer: buffer=%s item=%s error=%w", name, entry.Item.ID, err)
+	}
+
+	return int(length.Val()), nil
+}
+
+// ReadBuffer returns the items of a named buffer, oldest first
+func (s *RedisStore) ReadBuffer(ctx context.Context, name string) ([]domain.BufferedItem, error) {
+	defer metrics.ObserveStore("read_buffer", time.Now())
+
+	values, err := s.client.HGetAll(ctx, bufferKey(name)).Result()
+	if err != nil {
+		return nil, fmt.Errorf("failed to read buffer: buffer=%s error=%w", name, err)
+	}
+
+	entries := make([]domain.BufferedItem, 0, len(values))
+	for id, data := range values {
+		entry, err := decodeBufferedItem([]byte(data))
+		if err != nil {
+			logger.Error("Failed to decode buffered item: buffer=%s item=%s error=%v", name, id, err)
+			continue
+		}
+		entries = append(entries, entry)
+	}
+
+	sort.Slice(entries, func(i, j int) bool {
+		return entries[i].Item.PublishedAt.Before(entries[j].Item.PublishedAt)
+	})
+
+	return entries, nil
+}
+
+// decodeBufferedItem decodes a buffered item, including the bare items
+// buffered by earlier versions
+func decodeBufferedItem(data []byte) (domain.BufferedItem, error) {
+	var entry domain.BufferedItem
+	if err := json.Unmarshal(data, &entry); err != nil {
+		return entry, err
+	}
+	if entry.Item.ID != "" {
+		return entry, nil
+	}
+
+	err := json.Unmarshal(data, &entry.Item)
+	return entry, err
+}
+
+// RemoveFromBuffer deletes items from a named buffer
+func (s *RedisStore) RemoveFromBuffer(ctx context.Context, name string, itemIDs []string) error {
+	defer metrics.ObserveStore("remove_from_buffer", time.Now())
+
+	if len(itemIDs) == 0 {
+		return nil
+	}
+
+	if err := s.client.HDel(ctx, bufferKey(name), itemIDs...).Err(); err != nil {
+		return fmt.Errorf("failed to remove from buffer: buffer=%s error=%w", name, err)
+	}
+
+	return nil
+}
+
+// MigrateLegacyProcessedKeys copies processed keys written under the legacy
+// exporter type (e.g. "bridgr:processed:webhook:<item>") to every given
+// exporter ID, preserving their TTL, then removes the legacy keys.
+// The migration runs only once; subsequent calls are no-ops. Without any
+// exporter to migrate to, the legacy keys are kept for a later run.
+func (s *RedisStore) MigrateLegacyProcessedKeys(ctx context.Context, legacyType string, exporterIDs []string) (int, error) {
+	done, err := s.client.SIsMember(ctx, exporterIDMigrationKey, legacyType).Result()
+	if err != nil {
+		return 0, fmt.Errorf("failed to check migration status: %w", err)
+	}
+	if done {
+		return 0, nil
+	}
+
+	// An exporter explicitly named after the legacy type already owns these keys
+	owned := false
+	targets := make([]string, 0, len(exporterIDs))
+	for _, id := range exporterIDs {
+		if id == legacyType {
+			owned = true
+			continue
+		}
+		targets = append(targets, id)
+	}
+
+	if len(targets) == 0 {
+		if !owned {
+			// Keep the legacy keys until an exporter of that type is configured
+			return 0, nil
+		}
+		if err := s.client.SAdd(ctx, exporterIDMigrationKey, legacyType).Err(); err != nil {
+			return 0, fmt.Errorf("failed to record migration: %w", err)
+		}
+		return 0, nil
+	}
+
+	prefix := processedKey(legacyType, "")
+	migrated := 0
+
+	iter := s.client.Scan(ctx, 0, prefix+"*", 0).Iterator()
+	for iter.Next(ctx) {
+		key := iter.Val()
+		itemID := strings.TrimPrefix(key, prefix)
+
+		ttl, err := s.client.PTTL(ctx, key).Result()
+		if err != nil {
+			return migrated, fmt.Errorf("failed to get TTL for key: key=%s error=%w", key, err)
+		}
+		if ttl == -2*time.Nanosecond {
+			// Key expired while scanning
+			continue
+		}
+		if ttl < 0 {
+			ttl = s.config.TTL
+		}
+
+		for _, id := range targets {
+			if err := s.client.SetNX(ctx, processedKey(id, itemID), "1", ttl).Err(); err != nil {
+				return migrated, fmt.Errorf("failed to migrate key: key=%s exporter=%s error=%w", key, id, err)
+			}
+		}
+
+		if err := s.client.Del(ctx, key).Err(); err != nil {
+			return migrated, fmt.Errorf("failed to delete legacy key: key=%s error=%w", key, err)
+		}
+		migrated++
+	}
+
+	if err := iter.Err(); err != nil {
+		return migrated, fmt.Errorf("failed to scan keys: %w", err)
+	}
+
+	if err := s.client.SAdd(ctx, exporterIDMigrationKey, legacyType).Err(); err != nil {
+		return migrated, fmt.Errorf("failed to record migration: %w", err)
+	}
+
+	return migrated, nil
+}
+
+// Ping checks that Redis is reachable
+func (s *RedisStore) Ping(ctx context.Context) error {
+	defer metrics.ObserveStore("ping", time.Now())
+
+	if err := s.client.Ping(ctx).Err(); err != nil {
+		return fmt.Errorf("failed to ping Redis: %w", err)
+	}
+
+	return nil
+}
+
 // Close closes the Redis connection
 func (s *RedisStore) Close() error {
 	return s.client.Close()
 }
 
 // Cleanup removes expired keys
-func (s *RedisStore) Cleanup() error {
-	ctx := context.Background()
-	pattern := "bridgr:processed:*"
+func (s *RedisStore) Cleanup(ctx context.Context) error {
+	pattern := processedKeyPrefix + ":*"
 
 	iter := s.client.Scan(ctx, 0, pattern, 0).Iterator()
 	for iter.Next(ctx) {
@@ -101,4 +419,19 @@ func (s *RedisStore) Cleanup() error {
 	}
 
 	return nil
-} 
\ No newline at end of file
+} 
+
+// processedKey builds the deduplication key for an item and an exporter
+func processedKey(exporterID, itemID string) string {
+	return fmt.Sprintf("%s:%s:%s", processedKeyPrefix, exporterID, itemID)
+}
+
+// sourceKey builds the key holding a piece of source state
+func sourceKey(sourceID, name string) string {
+	return fmt.Sprintf("%s:%s:%s", sourceKeyPrefix, sourceID, name)
+}
+
+// bufferKey builds the key holding a named item buffer
+func bufferKey(name string) string {
+	return fmt.Sprintf("%s:%s", bufferKeyPrefix, name)
+}
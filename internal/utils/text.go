@@ -0,0 +1,14 @@
+package utils
+
+// Truncate shortens a string to at most max runes, ending it with an
+// ellipsis when it had to be cut
+func Truncate(text string, max int) string {
+	runes := []rune(text)
+	if len(runes) <= max {
+		return text
+	}
+	if max <= 1 {
+		return string(runes[:max])
+	}
+	return string(runes[:max-1]) + "…"
+}
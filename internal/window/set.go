@@ -0,0 +1,42 @@
+package window
+
+import (
+	"fmt"
+
+	"github.com/leofvo/bridgr/internal/config"
+)
+
+// Set holds the delivery windows of the exporters, keyed by exporter ID
+type Set struct {
+	windows map[string]*Window
+}
+
+// NewSet compiles the delivery windows of a configuration
+func NewSet(cfg *config.Config) (*Set, error) {
+	s := &Set{
+		windows: make(map[string]*Window),
+	}
+
+	for _, group := range cfg.Groups {
+		for i := range group.Exporters {
+			exporter := &group.Exporters[i]
+			w, err := New(exporter.Window)
+			if err != nil {
+				return nil, fmt.Errorf("failed to compile exporter window: type=%s error=%w", exporter.Type, err)
+			}
+			if w != nil {
+				s.windows[exporter.ResolveID(group.Name)] = w
+			}
+		}
+	}
+
+	return s, nil
+}
+
+// Get returns the window of an exporter, or nil if it always delivers
+func (s *Set) Get(exporterID string) *Window {
+	if s == nil {
+		return nil
+	}
+	return s.windows[exporterID]
+}
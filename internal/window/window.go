@@ -0,0 +1,110 @@
+package window
+
+import (
+	"fmt"
+	"time"
+
+	// Embed the timezone database for images without one
+	_ "time/tzdata"
+
+	"github.com/leofvo/bridgr/internal/config"
+)
+
+// period is a compiled window period
+type period struct {
+	days  map[time.Weekday]bool
+	start time.Duration
+	end   time.Duration
+}
+
+// Window tells whether deliveries are allowed at a given time
+type Window struct {
+	location *time.Location
+	periods  []period
+	collapse bool
+}
+
+// New compiles a window configuration. A nil configuration returns a nil
+// window, which is always open.
+func New(cfg *config.WindowConfig) (*Window, error) {
+	if cfg == nil {
+		return nil, nil
+	}
+
+	location, err := cfg.Location()
+	if err != nil {
+		return nil, err
+	}
+
+	w := &Window{
+		location: location,
+		collapse: cfg.Collapse,
+	}
+
+	for _, p := range cfg.Periods {
+		compiled := period{}
+		if len(p.Days) > 0 {
+			compiled.days = make(map[time.Weekday]bool, len(p.Days))
+			for _, day := range p.Days {
+				weekday, err := config.ParseWeekday(day)
+				if err != nil {
+					return nil, err
+				}
+				compiled.days[weekday] = true
+			}
+		}
+
+		if compiled.start, err = config.ParseClock(p.Start); err != nil {
+			return nil, err
+		}
+		if compiled.end, err = config.ParseClock(p.End); err != nil {
+			return nil, err
+		}
+		if compiled.start == compiled.end {
+			return nil, fmt.Errorf("period start and end cannot be equal: %s", p.Start)
+		}
+
+		w.periods = append(w.periods, compiled)
+	}
+
+	return w, nil
+}
+
+// Open reports whether deliveries are allowed at the given time
+func (w *Window) Open(t time.Time) bool {
+	if w == nil {
+		return true
+	}
+
+	local := t.In(w.location)
+	clock := time.Duration(local.Hour())*time.Hour + time.Duration(local.Minute())*time.Minute + time.Duration(local.Second())*time.Second
+	today := local.Weekday()
+	yesterday := (today + 6) % 7
+
+	for _, p := range w.periods {
+		if p.start < p.end {
+			if p.onDay(today) && clock >= p.start && clock < p.end {
+				return true
+			}
+			continue
+		}
+
+		// The period spans midnight and belongs to the day it starts
+		if (p.onDay(today) && clock >= p.start) || (p.onDay(yesterday) && clock < p.end) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// Collapse reports whether the items held while the window was closed are
+// released as a single digest
+func (w *Window) Collapse() bool {
+	return w != nil && w.collapse
+}
+
+// onDay reports whether a period applies to a weekday
+func (p period) onDay(day time.Weekday) bool {
+	return p.days == nil || p.days[day]
+}
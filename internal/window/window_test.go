@@ -0,0 +1,164 @@
+package window
+
+import (
+	"testing"
+	"time"
+
+	"github.com/leofvo/bridgr/internal/config"
+)
+
+// monday is 2024-05-06 at midnight UTC
+var monday = time.Date(2024, 5, 6, 0, 0, 0, 0, time.UTC)
+
+// at returns the time on a day after monday, at the given UTC time of day
+func at(days int, clock string) time.Time {
+	offset, err := config.ParseClock(clock)
+	if err != nil {
+		panic(err)
+	}
+	return monday.AddDate(0, 0, days).Add(offset)
+}
+
+func TestWindowOpen(t *testing.T) {
+	tests := []struct {
+		name   string
+		config *config.WindowConfig
+		time   time.Time
+		want   bool
+	}{
+		{
+			name:   "no window",
+			config: nil,
+			time:   at(0, "03:00"),
+			want:   true,
+		},
+		{
+			name:   "before daytime period",
+			config: &config.WindowConfig{Periods: []config.WindowPeriod{{Start: "08:00", End: "19:00"}}},
+			time:   at(0, "07:59"),
+			want:   false,
+		},
+		{
+			name:   "start of daytime period",
+			config: &config.WindowConfig{Periods: []config.WindowPeriod{{Start: "08:00", End: "19:00"}}},
+			time:   at(0, "08:00"),
+			want:   true,
+		},
+		{
+			name:   "end of daytime period",
+			config: &config.WindowConfig{Periods: []config.WindowPeriod{{Start: "08:00", End: "19:00"}}},
+			time:   at(0, "19:00"),
+			want:   false,
+		},
+		{
+			name:   "weekday period on a weekday",
+			config: &config.WindowConfig{Periods: []config.WindowPeriod{{Days: []string{"mon", "tue", "wed", "thu", "fri"}, Start: "09:00", End: "17:00"}}},
+			time:   at(0, "10:00"),
+			want:   true,
+		},
+		{
+			name:   "weekday period on a weekend",
+			config: &config.WindowConfig{Periods: []config.WindowPeriod{{Days: []string{"mon", "tue", "wed", "thu", "fri"}, Start: "09:00", End: "17:00"}}},
+			time:   at(5, "10:00"),
+			want:   false,
+		},
+		{
+			name:   "midnight span before midnight",
+			config: &config.WindowConfig{Periods: []config.WindowPeriod{{Start: "22:00", End: "06:00"}}},
+			time:   at(0, "23:00"),
+			want:   true,
+		},
+		{
+			name:   "midnight span after midnight",
+			config: &config.WindowConfig{Periods: []config.WindowPeriod{{Start: "22:00", End: "06:00"}}},
+			time:   at(1, "03:00"),
+			want:   true,
+		},
+		{
+			name:   "midnight span during the day",
+			config: &config.WindowConfig{Periods: []config.WindowPeriod{{Start: "22:00", End: "06:00"}}},
+			time:   at(1, "12:00"),
+			want:   false,
+		},
+		{
+			name:   "midnight span continues on the next day",
+			config: &config.WindowConfig{Periods: []config.WindowPeriod{{Days: []string{"friday"}, Start: "22:00", End: "02:00"}}},
+			time:   at(5, "01:00"),
+			want:   true,
+		},
+		{
+			name:   "midnight span belongs to the day it starts",
+			config: &config.WindowConfig{Periods: []config.WindowPeriod{{Days: []string{"friday"}, Start: "22:00", End: "02:00"}}},
+			time:   at(4, "01:00"),
+			want:   false,
+		},
+		{
+			name:   "time zone inside period",
+			config: &config.WindowConfig{Timezone: "Europe/Paris", Periods: []config.WindowPeriod{{Start: "08:00", End: "19:00"}}},
+			time:   at(0, "06:30"),
+			want:   true,
+		},
+		{
+			name:   "time zone outside period",
+			config: &config.WindowConfig{Timezone: "Europe/Paris", Periods: []config.WindowPeriod{{Start: "08:00", End: "19:00"}}},
+			time:   at(0, "17:30"),
+			want:   false,
+		},
+		{
+			name:   "time zone on the previous local day",
+			config: &config.WindowConfig{Timezone: "America/New_York", Periods: []config.WindowPeriod{{Days: []string{"mon"}, Start: "20:00", End: "23:00"}}},
+			time:   at(1, "01:00"),
+			want:   true,
+		},
+		{
+			name: "any of several periods",
+			config: &config.WindowConfig{Periods: []config.WindowPeriod{
+				{Start: "08:00", End: "12:00"},
+				{Start: "14:00", End: "18:00"},
+			}},
+			time: at(0, "15:00"),
+			want: true,
+		},
+		{
+			name: "between several periods",
+			config: &config.WindowConfig{Periods: []config.WindowPeriod{
+				{Start: "08:00", End: "12:00"},
+				{Start: "14:00", End: "18:00"},
+			}},
+			time: at(0, "13:00"),
+			want: false,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w, err := New(tt.config)
+			if err != nil {
+				t.Fatalf("New() error = %v", err)
+			}
+			if got := w.Open(tt.time); got != tt.want {
+				t.Errorf("Open(%v) = %v, want %v", tt.time, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestNewInvalid(t *testing.T) {
+	tests := []struct {
+		name   string
+		config *config.WindowConfig
+	}{
+		{name: "unknown time zone", config: &config.WindowConfig{Timezone: "Mars/Olympus", Periods: []config.WindowPeriod{{Start: "08:00", End: "19:00"}}}},
+		{name: "unknown day", config: &config.WindowConfig{Periods: []config.WindowPeriod{{Days: []string{"someday"}, Start: "08:00", End: "19:00"}}}},
+		{name: "invalid start", config: &config.WindowConfig{Periods: []config.WindowPeriod{{Start: "8am", End: "19:00"}}}},
+		{name: "equal start and end", config: &config.WindowConfig{Periods: []config.WindowPeriod{{Start: "08:00", End: "08:00"}}}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := New(tt.config); err == nil {
+				t.Error("New() error = nil, want an error")
+			}
+		})
+	}
+}
@@ -3,14 +3,21 @@ package logger
 import (
 	"fmt"
 	"os"
+	"time"
 
 	"github.com/sirupsen/logrus"
 )
 
 var log = logrus.New()
 
-// Init initializes the logger with the specified level
-func Init(level string) error {
+// Log output formats
+const (
+	FormatText = "text"
+	FormatJSON = "json"
+)
+
+// Init initializes the logger with the specified level and output format
+func Init(level, format string) error {
 	// Parse log level
 	logLevel, err := logrus.ParseLevel(level)
 	if err != nil {
@@ -20,11 +27,21 @@ func Init(level string) error {
 	// Configure logger
 	log.SetLevel(logLevel)
 	log.SetOutput(os.Stdout)
-	log.SetFormatter(&logrus.TextFormatter{
-		FullTimestamp:   true,
-		TimestampFormat: "2006-01-02 15:04:05",
-		DisableColors:   false,
-	})
+
+	switch format {
+	case FormatText, "":
+		log.SetFormatter(&redactingFormatter{&logrus.TextFormatter{
+			FullTimestamp:   true,
+			TimestampFormat: "2006-01-02 15:04:05",
+			DisableColors:   false,
+		}})
+	case FormatJSON:
+		log.SetFormatter(&redactingFormatter{&logrus.JSONFormatter{
+			TimestampFormat: time.RFC3339,
+		}})
+	default:
+		return fmt.Errorf("unknown log format: %s", format)
+	}
 
 	return nil
 }
@@ -0,0 +1,77 @@
+package logger
+
+import (
+	"encoding/json"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/sirupsen/logrus"
+)
+
+// redactedText replaces the secrets in the log output
+const redactedText = "[REDACTED]"
+
+var (
+	redactMu sync.RWMutex
+	redactor *strings.Replacer
+)
+
+// SetSecrets sets the values redacted from the log output, replacing the
+// previous ones
+func SetSecrets(secrets []string) {
+	var replacements []string
+	seen := make(map[string]bool)
+	for _, secret := range sortedByLength(secrets) {
+		// Secrets also appear escaped in JSON output
+		escaped, _ := json.Marshal(secret)
+		for _, variant := range []string{secret, string(escaped[1 : len(escaped)-1])} {
+			if variant != "" && !seen[variant] {
+				seen[variant] = true
+				replacements = append(replacements, variant, redactedText)
+			}
+		}
+	}
+
+	redactMu.Lock()
+	defer redactMu.Unlock()
+	if len(replacements) == 0 {
+		redactor = nil
+		return
+	}
+	redactor = strings.NewReplacer(replacements...)
+}
+
+// Redact replaces the secrets in a text
+func Redact(text string) string {
+	redactMu.RLock()
+	defer redactMu.RUnlock()
+	if redactor == nil {
+		return text
+	}
+	return redactor.Replace(text)
+}
+
+// sortedByLength returns the secrets longest first, so that a secret
+// containing another one is redacted as a whole
+func sortedByLength(secrets []string) []string {
+	sorted := append([]string(nil), secrets...)
+	sort.SliceStable(sorted, func(i, j int) bool {
+		return len(sorted[i]) > len(sorted[j])
+	})
+	return sorted
+}
+
+// redactingFormatter redacts the secrets from the output of a formatter
+type redactingFormatter struct {
+	logrus.Formatter
+}
+
+// Format formats an entry and redacts the secrets it contains
+func (f *redactingFormatter) Format(entry *logrus.Entry) ([]byte, error) {
+	data, err := f.Formatter.Format(entry)
+	if err != nil {
+		return nil, err
+	}
+	return []byte(Redact(string(data))), nil
+}